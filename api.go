@@ -0,0 +1,752 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- API VIEWS ---
+
+type GameView struct {
+	GameID   string     `json:"game_id"`
+	Sport    string     `json:"sport"`
+	League   string     `json:"league"`
+	Home     string     `json:"home_team"`
+	Away     string     `json:"away_team"`
+	Scores   string     `json:"scores"`
+	Time     string     `json:"time_status"`
+	StartsAt *time.Time `json:"starts_at"`
+
+	StartsAtEstimated bool        `json:"starts_at_estimated"`
+	ScoresDetail      *ScoreBoard `json:"scores_detail,omitempty"`
+}
+
+type OddView struct {
+	Bookmaker      string   `json:"bookmaker"`
+	MarketID       string   `json:"market_id"`
+	MarketName     string   `json:"market_name"`
+	MarketKey      string   `json:"market_key"`
+	MarketShape    string   `json:"market_shape"`
+	SelectionCount int      `json:"selection_count"`
+	SelectionID    string   `json:"selection_id"`
+	SelectionName  string   `json:"selection_name"`
+	Line           string   `json:"line"`
+	LineType       string   `json:"line_type"` // handicap / total / other / none; "" — ещё не пересчитано
+	PriceDec       *string  `json:"price_dec"` // строкой — точное значение NUMERIC
+	Price          *float64 `json:"price"`     // то же числом, для клиентов; null если цены нет
+	PriceFrac      string   `json:"price_frac"`
+	PriceHK        *float64 `json:"price_hk,omitempty"`   // ?format=hongkong
+	PriceIndo      *float64 `json:"price_indo,omitempty"` // ?format=indonesian
+	IsSuspended    bool     `json:"is_suspended"`
+	Status         string   `json:"status"` // open / suspended / closed
+	IsNew          bool     `json:"is_new"` // появился в пределах NEW_SELECTION_WINDOW
+}
+
+// ListOddView — облегчённый исход для списка /api/games.
+type ListOddView struct {
+	Bookmaker      string   `json:"bookmaker"`
+	MarketID       string   `json:"market_id"`
+	MarketName     string   `json:"market_name"`
+	MarketShape    string   `json:"market_shape"`
+	SelectionCount int      `json:"selection_count"`
+	SelectionName  string   `json:"selection_name"`
+	PriceDec       *string  `json:"price_dec"`
+	Price          *float64 `json:"price"`
+	PriceHK        *float64 `json:"price_hk,omitempty"`
+	PriceIndo      *float64 `json:"price_indo,omitempty"`
+	IsSuspended    bool     `json:"is_suspended"`
+	Status         string   `json:"status"`
+	IsNew          bool     `json:"is_new"`
+}
+
+// Поля элемента списка /api/games, которые можно запросить через ?fields=
+var gameListFields = []string{"game_id", "league", "home_team", "away_team", "time_status", "starts_at", "starts_at_estimated", "scores_detail", "odds", "market_count"}
+
+// Колонки сортировки /api/games; в SQL попадают только эти фиксированные имена.
+// game_id всегда добавляется вторым ключом, чтобы при равных значениях порядок
+// не менялся между страницами limit/offset.
+var gameSortColumns = map[string]string{
+	"starts_at": "starts_at",
+	"league":    "league",
+	"home_team": "home_team",
+}
+
+var marketsCache = newTTLCache("markets", 30*time.Second)
+
+// --- HANDLERS ---
+
+// GET /api/games
+//
+//	fields=game_id,home_team — вернуть только перечисленные поля
+//	include=odds             — подгружать коэффициенты; если include задан без odds, join пропускается
+//	include=market_count     — число рынков матча (distinct market_id, кроме закрытых), одним запросом на страницу
+//	exclude_suspended=true   — не отдавать приостановленные исходы
+//	primary_odds_only=true   — только основной рынок матча (PRIMARY_MARKET_<SPORT>)
+//	include_stale=true       — включить матчи, пропавшие из фида (GAME_MISSING_SYNCS синков подряд)
+//	date=2024-06-15, tz=Europe/Moscow — все матчи календарного дня в поясе tz (UTC), включая
+//	                           завершённые; по умолчанию сортировка по времени начала
+//	since=<RFC3339>          — только матчи, обновлённые позже; в ответе server_time для следующего запроса
+//	                           и removed — ID матчей, пропавших из выдачи (см. loadRemovedGames)
+//	has_odds=true            — только матчи, по которым уже есть коэффициенты
+//	sort=starts_at|league|home_team, order=asc|desc — сортировка (по умолчанию starts_at asc)
+//	limit=100, offset=0      — пагинация (limit до 500)
+//	format=hongkong,indonesian — добавить price_hk / price_indo к исходам (см. oddsformat.go)
+//	debug=true               — (только с админ-токеном) SQL, параметры и статистика запроса
+//
+// Ответ с ETag: If-None-Match с тем же значением даёт 304 без тела (server_time в ETag не входит).
+// С Accept: application/x-protobuf ответ — GameList из proto/games.proto (fields= и debug не влияют
+// на состав сообщения, кроме odds).
+func listGamesHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		fields, err := queryList(c, "fields", gameListFields...)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		include, err := queryList(c, "include", "odds", "market_count")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		withOdds := true
+		if _, ok := c.GetQuery("include"); ok {
+			withOdds = slices.Contains(include, "odds")
+		}
+		if len(fields) > 0 && !slices.Contains(fields, "odds") {
+			withOdds = false
+		}
+		withMarketCount := slices.Contains(include, "market_count") || slices.Contains(fields, "market_count")
+
+		since, err := queryTime(c, "since")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		dayFrom, dayTo, byDay, err := queryDay(c, "date", "tz")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		excludeSuspended, err := queryBool(c, "exclude_suspended", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		primaryOnly, err := queryBool(c, "primary_odds_only", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		includeStale, err := queryBool(c, "include_stale", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		hasOdds, err := queryBool(c, "has_odds", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		sortBy, err := queryEnum(c, "sort", "starts_at", "starts_at", "league", "home_team")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		order, err := queryEnum(c, "order", "asc", "asc", "desc")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		limit, err := queryInt(c, "limit", 100, 1, 500)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		offset, err := queryInt(c, "offset", 0, 0, 1_000_000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		formats, err := queryOddsFormats(c)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		debug, err := queryBool(c, "debug", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		if debug && !isAdmin(c) {
+			c.JSON(401, gin.H{"error": "debug requires admin token"})
+			return
+		}
+
+		serverTime, err := gamesServerTime(c.Request.Context(), db)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+
+		var args []any
+		var where []string
+		if byDay {
+			// для выбора даты нужны все матчи дня, в том числе уже сыгранные
+			args = append(args, dayFrom, dayTo)
+			where = append(where, fmt.Sprintf("starts_at >= $%d AND starts_at < $%d", len(args)-1, len(args)))
+		} else {
+			args = append(args, activeTimeStatuses)
+			where = append(where, fmt.Sprintf("time_status = ANY($%d)", len(args)))
+		}
+		if since != nil {
+			args = append(args, *since)
+			where = append(where, fmt.Sprintf("updated_at > $%d", len(args)))
+		}
+		if !includeStale {
+			args = append(args, missingSyncsLimit())
+			where = append(where, fmt.Sprintf("missed_syncs < $%d", len(args)))
+		}
+		if hasOdds {
+			// EXISTS, а не JOIN: строки матчей не размножаются по числу исходов
+			where = append(where, "EXISTS (SELECT 1 FROM liveodds o WHERE o.game_id = games.game_id)")
+		}
+
+		args = append(args, limit, offset)
+		query := `
+		SELECT game_id, sport, league, home_team, away_team, time_status, starts_at, starts_at_estimated, scores_detail
+		FROM games
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + gameSortColumns[sortBy] + " " + strings.ToUpper(order) + ` NULLS LAST, game_id
+		LIMIT ` + fmt.Sprintf("$%d OFFSET $%d", len(args)-1, len(args)) + `
+	`
+		started := time.Now()
+		rows, err := db.Query(c.Request.Context(), query, args...)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		// Матчи дочитываются до запросов коэффициентов: rows закрыт и соединение вернулось в пул
+		games, err := scanAll(rows, func(r rowScanner) (GameView, error) {
+			var g GameView
+			err := r.Scan(&g.GameID, &g.Sport, &g.League, &g.Home, &g.Away, &g.Time, &g.StartsAt, &g.StartsAtEstimated, &g.ScoresDetail)
+			return g, err
+		})
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+
+		var marketCounts map[string]int
+		if withMarketCount {
+			ids := make([]string, len(games))
+			for i, g := range games {
+				ids[i] = g.GameID
+			}
+			if marketCounts, err = loadMarketCounts(c.Request.Context(), db, ids); err != nil {
+				serverError(c, err)
+				return
+			}
+		}
+
+		// С since — ещё и ID матчей, которые с тех пор пропали из выдачи: завершились,
+		// скрыты по missed_syncs или удалены. Клиент убирает их у себя.
+		removed := []string{}
+		if since != nil {
+			if removed, err = loadRemovedGames(c.Request.Context(), db, *since, byDay, includeStale); err != nil {
+				serverError(c, err)
+				return
+			}
+		}
+
+		var out []map[string]any
+		asProtobuf := wantsProtobuf(c)
+		var pbGames []gameListItem
+
+		for _, g := range games {
+			item := map[string]any{
+				"game_id":             g.GameID,
+				"league":              g.League,
+				"home_team":           g.Home,
+				"away_team":           g.Away,
+				"time_status":         g.Time,
+				"starts_at":           g.StartsAt,
+				"starts_at_estimated": g.StartsAtEstimated,
+			}
+			if g.ScoresDetail != nil {
+				item["scores_detail"] = g.ScoresDetail
+			}
+			var marketCount *int
+			if withMarketCount {
+				n := marketCounts[g.GameID]
+				marketCount = &n
+				item["market_count"] = n
+			}
+			if withOdds {
+				var markets []string
+				if primaryOnly {
+					markets = primaryMarkets(g.Sport)
+				}
+				odds, failed := listOdds(c.Request.Context(), db, g.GameID, excludeSuspended, markets)
+				formats.applyList(odds)
+				item["odds"] = odds
+				if failed {
+					item["odds_error"] = true
+				}
+				if asProtobuf {
+					pbGames = append(pbGames, gameListItem{GameView: g, Odds: odds, OddsError: failed, MarketCount: marketCount})
+				}
+			} else if asProtobuf {
+				pbGames = append(pbGames, gameListItem{GameView: g, MarketCount: marketCount})
+			}
+			if len(fields) > 0 {
+				picked := make(map[string]any, len(fields))
+				for _, f := range fields {
+					picked[f] = item[f]
+				}
+				if failed, ok := item["odds_error"]; ok && slices.Contains(fields, "odds") {
+					picked["odds_error"] = failed
+				}
+				item = picked
+			}
+			out = append(out, item)
+		}
+
+		// ETag — от самих данных: коэффициенты меняются без updated_at матча,
+		// поэтому max(updated_at) пропустил бы обновления odds. removed тоже часть ответа:
+		// без него новый пропавший матч при тех же играх дал бы 304. server_time не входит —
+		// он свой у каждого запроса, и 304 был бы невозможен.
+		if !debug {
+			var games any = out
+			if asProtobuf {
+				games = pbGames
+			}
+			if notModified(c, weakETag(c, gin.H{"games": games, "removed": removed})) {
+				return
+			}
+		}
+
+		if asProtobuf {
+			respondProtobuf(c, pbGames, removed, limit, offset, serverTime)
+			return
+		}
+
+		extra := gin.H{"server_time": serverTime}
+		if since != nil {
+			extra["removed"] = removed
+		}
+		if debug {
+			extra["debug"] = gin.H{
+				"query":       query,
+				"params":      args,
+				"rows":        len(out),
+				"with_odds":   withOdds,
+				"duration_ms": time.Since(started).Milliseconds(),
+			}
+		}
+		respondList(c, "games", out, len(out), limit, offset, extra)
+	}
+}
+
+// gamesServerTime — метка для следующего ?since=. updated_at проставляет БД (clock_timestamp()
+// в момент записи строки), а видна строка только после commit, поэтому метка не может быть
+// позже начала самой старой ещё не закоммиченной пишущей транзакции: её строки получат
+// updated_at не раньше этого момента. На реплике чужие транзакции не видны — берём время
+// последней применённой транзакции минус SINCE_REPLICA_OVERLAP (60s); матчи из этого окна
+// придут повторно, но ни один не потеряется, если синк коммитится быстрее окна.
+func gamesServerTime(ctx context.Context, db *pgxpool.Pool) (time.Time, error) {
+	var t time.Time
+	err := db.QueryRow(ctx, `
+		SELECT CASE WHEN pg_is_in_recovery()
+			THEN COALESCE(pg_last_xact_replay_timestamp(), now()) - make_interval(secs => $1)
+			ELSE LEAST(now(), (
+				SELECT min(xact_start) FROM pg_stat_activity
+				WHERE datname = current_database() AND backend_xid IS NOT NULL))
+		END`, getEnvDuration("SINCE_REPLICA_OVERLAP", time.Minute).Seconds()).Scan(&t)
+	return t, err
+}
+
+// loadRemovedGames — ID матчей, которые после since перестали попадать в /api/games:
+// статус стал неактивным или матч скрыт по missed_syncs (updated_at двигается в обоих случаях),
+// либо матч удалён (deleted_games). С date= статус не фильтруется — в removed только удалённые и скрытые.
+func loadRemovedGames(ctx context.Context, db *pgxpool.Pool, since time.Time, byDay, includeStale bool) ([]string, error) {
+	rows, err := db.Query(ctx, `
+		SELECT game_id FROM games
+		WHERE updated_at > $1
+		  AND NOT (($2 OR time_status = ANY($3)) AND ($4 OR missed_syncs < $5))
+		UNION ALL
+		SELECT d.game_id FROM deleted_games d
+		WHERE d.deleted_at > $1
+		  AND NOT EXISTS (SELECT 1 FROM games g WHERE g.game_id = d.game_id)
+		ORDER BY 1`, since, byDay, activeTimeStatuses, includeStale, missingSyncsLimit())
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scanAll(rows, scanString)
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, err
+}
+
+// loadListOdds — облегчённый набор коэффициентов для списка матчей.
+// markets == nil — все рынки, иначе только с market_id или названием из списка.
+func loadListOdds(ctx context.Context, db *pgxpool.Pool, gameID string, excludeSuspended bool, markets []string) ([]ListOddView, error) {
+	oddsRows, err := db.Query(ctx, `
+		SELECT bookmaker, market_id, market_name, market_shape, selection_count, selection_name, price_dec::text, price_dec::float8, is_suspended, status,
+		       `+isNewExpr("")+`
+		FROM liveodds
+		WHERE game_id = $1 AND NOT ($2 AND is_suspended)
+		  AND ($3::text[] IS NULL OR lower(market_id) = ANY($3) OR lower(market_name) = ANY($3))
+		ORDER BY market_id, selection_id, bookmaker`,
+		gameID, excludeSuspended, markets,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer oddsRows.Close()
+
+	var odds []ListOddView
+	for oddsRows.Next() {
+		var o ListOddView
+		if err := oddsRows.Scan(&o.Bookmaker, &o.MarketID, &o.MarketName, &o.MarketShape, &o.SelectionCount,
+			&o.SelectionName, &o.PriceDec, &o.Price, &o.IsSuspended, &o.Status, &o.IsNew); err != nil {
+			return nil, err
+		}
+		odds = append(odds, o)
+	}
+	return odds, oddsRows.Err()
+}
+
+// loadMarketCounts — число незакрытых рынков (distinct market_id) по матчам одним запросом.
+// Матчей без коэффициентов в результате нет — для них 0.
+func loadMarketCounts(ctx context.Context, db *pgxpool.Pool, gameIDs []string) (map[string]int, error) {
+	rows, err := db.Query(ctx, `
+		SELECT game_id, COUNT(DISTINCT market_id)
+		FROM liveodds
+		WHERE game_id = ANY($1) AND status <> 'closed'
+		GROUP BY game_id`, gameIDs)
+	if err != nil {
+		return nil, err
+	}
+	type count struct {
+		gameID string
+		n      int
+	}
+	counts, err := scanAll(rows, func(r rowScanner) (count, error) {
+		var c count
+		err := r.Scan(&c.gameID, &c.n)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int, len(counts))
+	for _, c := range counts {
+		out[c.gameID] = c.n
+	}
+	return out, nil
+}
+
+// listOdds — коэффициенты матча для списков. При ошибке запроса матч всё равно отдаётся,
+// но с пустыми odds и флагом odds_error: для UI это «коэффициенты недоступны», а не «их нет».
+func listOdds(ctx context.Context, db *pgxpool.Pool, gameID string, excludeSuspended bool, markets []string) ([]ListOddView, bool) {
+	odds, err := loadListOdds(ctx, db, gameID, excludeSuspended, markets)
+	if err != nil {
+		logf(ctx, "❌ Odds query error for game %s: %v", gameID, err)
+		return []ListOddView{}, true
+	}
+	return odds, false
+}
+
+// GET /api/games/:id — матч и его рынки, сгруппированные по market_key.
+// ?market=40,totals — только рынки с таким market_id или market_key (через запятую),
+// чтобы UI подгружал отдельные рынки по требованию.
+func gameDetailHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		id := c.Param("id")
+		formats, err := queryOddsFormats(c)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		var marketFilter []string
+		for _, m := range strings.Split(c.Query("market"), ",") {
+			if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+				marketFilter = append(marketFilter, m)
+			}
+		}
+
+		var g GameView
+		err = db.QueryRow(c.Request.Context(), `
+			SELECT game_id, sport, league, home_team, away_team, scores, time_status, starts_at, starts_at_estimated, scores_detail
+			FROM games WHERE game_id = $1`, id,
+		).Scan(&g.GameID, &g.Sport, &g.League, &g.Home, &g.Away, &g.Scores, &g.Time, &g.StartsAt, &g.StartsAtEstimated, &g.ScoresDetail)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(404, gin.H{"error": "game not found"})
+			return
+		}
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+
+		odds, err := loadGameOdds(c.Request.Context(), db, id, marketFilter)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+
+		// без фильтра все группы есть всегда; с фильтром — только те, где что-то нашлось
+		markets := map[string][]OddView{}
+		if marketFilter == nil {
+			for _, k := range []string{MarketKey1X2, MarketKeyTotals, MarketKeyBTTS, MarketKeyOther} {
+				markets[k] = []OddView{}
+			}
+		}
+		for _, o := range odds {
+			o.PriceHK, o.PriceIndo = formats.apply(o.Price)
+			markets[o.MarketKey] = append(markets[o.MarketKey], o)
+		}
+
+		c.JSON(200, gin.H{"game": g, "markets": markets})
+	}
+}
+
+// loadGameOdds — все коэффициенты матча; markets != nil — только с market_id или market_key из списка.
+func loadGameOdds(ctx context.Context, db *pgxpool.Pool, gameID string, markets []string) ([]OddView, error) {
+	rows, err := db.Query(ctx, `
+		SELECT bookmaker, market_id, market_name, market_key, market_shape, selection_count,
+		       selection_id, selection_name, line, line_type, price_dec::text, price_dec::float8, price_frac, is_suspended, status,
+		       `+isNewExpr("")+`
+		FROM liveodds
+		WHERE game_id = $1
+		  AND ($2::text[] IS NULL OR lower(market_id) = ANY($2) OR market_key = ANY($2))
+		ORDER BY market_id, selection_id, bookmaker`, gameID, markets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []OddView
+	for rows.Next() {
+		var o OddView
+		if err := rows.Scan(&o.Bookmaker, &o.MarketID, &o.MarketName, &o.MarketKey, &o.MarketShape, &o.SelectionCount,
+			&o.SelectionID, &o.SelectionName, &o.Line, &o.LineType, &o.PriceDec, &o.Price, &o.PriceFrac, &o.IsSuspended, &o.Status, &o.IsNew); err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// UpcomingGameView — матч для виджета «скоро начнётся» с коэффициентами основного рынка.
+type UpcomingGameView struct {
+	GameView
+	Odds      []ListOddView `json:"odds"`
+	OddsError bool          `json:"odds_error,omitempty"`
+}
+
+// GET /api/games/upcoming?within_minutes=30&limit=100 — prematch-матчи, начинающиеся в ближайшие N минут
+// (до суток), ближайшие первыми, не больше limit (до 1000). Матчи без starts_at не попадают,
+// пропавшие из фида — только с include_stale=true. Коэффициенты основного рынка — одним запросом на все матчи.
+func upcomingGamesHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		ctx := c.Request.Context()
+		within, err := queryInt(c, "within_minutes", 30, 1, 1440)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		limit, err := queryInt(c, "limit", 100, 1, 1000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		includeStale, err := queryBool(c, "include_stale", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		formats, err := queryOddsFormats(c)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		rows, err := db.Query(ctx, `
+			SELECT game_id, sport, league, home_team, away_team, scores, time_status, starts_at, starts_at_estimated, scores_detail
+			FROM games
+			WHERE source = 'pre' AND time_status = '0'
+			  AND starts_at >= now() AND starts_at < now() + make_interval(mins => $1)
+			  AND ($3 OR missed_syncs < $2)
+			ORDER BY starts_at, game_id
+			LIMIT $4`, within, missingSyncsLimit(), includeStale, limit)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		out := []UpcomingGameView{}
+		for rows.Next() {
+			var g UpcomingGameView
+			if err := rows.Scan(&g.GameID, &g.Sport, &g.League, &g.Home, &g.Away, &g.Scores, &g.Time, &g.StartsAt, &g.StartsAtEstimated, &g.ScoresDetail); err != nil {
+				rows.Close()
+				serverError(c, err)
+				return
+			}
+			out = append(out, g)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		// Коэффициенты — после закрытия rows и одним запросом на все матчи, как в /api/live
+		ids := make([]string, len(out))
+		var markets []string
+		for i, g := range out {
+			ids[i] = g.GameID
+			for _, m := range primaryMarkets(g.Sport) {
+				if !slices.Contains(markets, m) {
+					markets = append(markets, m)
+				}
+			}
+		}
+		odds, err := loadPrimaryOdds(ctx, db, ids, markets)
+		if err != nil {
+			logf(ctx, "❌ Upcoming odds query error: %v", err)
+		}
+		for i := range out {
+			out[i].Odds = []ListOddView{}
+			if err != nil {
+				out[i].OddsError = true
+				continue
+			}
+			out[i].Odds = primaryOdds(out[i].Sport, odds[out[i].GameID])
+			formats.applyList(out[i].Odds)
+		}
+		respondList(c, "games", out, len(out), limit, 0, gin.H{"within_minutes": within})
+	}
+}
+
+type MarketView struct {
+	MarketID   string `json:"market_id"`
+	MarketName string `json:"market_name"`
+	Selections int    `json:"selections"`
+}
+
+// GET /api/markets?sport=soccer — известные рынки, самые частые первыми.
+func listMarketsHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		sport, err := queryEnum(c, "sport", "", sports...)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		if cached, ok := marketsCache.get(sport); ok {
+			markets := cached.([]MarketView)
+			respondList(c, "markets", markets, len(markets), len(markets), 0, nil)
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT market_id, market_name, COUNT(*) AS selections
+			FROM liveodds
+			WHERE $1 = '' OR sport = $1
+			GROUP BY market_id, market_name
+			ORDER BY selections DESC, market_name`, sport)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []MarketView{}
+		for rows.Next() {
+			var m MarketView
+			if err := rows.Scan(&m.MarketID, &m.MarketName, &m.Selections); err != nil {
+				serverError(c, err)
+				return
+			}
+			out = append(out, m)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		marketsCache.set(sport, out)
+		respondList(c, "markets", out, len(out), len(out), 0, nil)
+	}
+}
+
+// GET /api/games/:id/related?limit=10 — другие активные матчи той же лиги.
+// Пропавшие из фида матчи скрыты, как в /api/games; include_stale=true их возвращает.
+func relatedGamesHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		limit, err := queryInt(c, "limit", 10, 1, 50)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		includeStale, err := queryBool(c, "include_stale", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		id := c.Param("id")
+		var league, sport string
+		err = db.QueryRow(c.Request.Context(), `SELECT league, sport FROM games WHERE game_id = $1`, id).Scan(&league, &sport)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(404, gin.H{"error": "game not found"})
+			return
+		}
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT game_id, sport, league, home_team, away_team, scores, time_status, starts_at, starts_at_estimated, scores_detail
+			FROM games
+			WHERE league = $1 AND sport = $2 AND game_id <> $3 AND time_status = ANY($5)
+			  AND ($6 OR missed_syncs < $7)
+			ORDER BY starts_at NULLS LAST, game_id
+			LIMIT $4`, league, sport, id, limit, activeTimeStatuses, includeStale, missingSyncsLimit())
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []GameView{}
+		for rows.Next() {
+			var g GameView
+			if err := rows.Scan(&g.GameID, &g.Sport, &g.League, &g.Home, &g.Away, &g.Scores, &g.Time, &g.StartsAt, &g.StartsAtEstimated, &g.ScoresDetail); err != nil {
+				serverError(c, err)
+				return
+			}
+			out = append(out, g)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		respondList(c, "games", out, len(out), limit, 0, gin.H{"game_id": id, "league": league})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIncludeStaleValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newDBHandle("DB", "", nil) // до БД запрос с неверным параметром не доходит
+	r := gin.New()
+	r.GET("/api/games", listGamesHandler(h))
+	r.GET("/api/games/upcoming", upcomingGamesHandler(h))
+	r.GET("/api/games/:id/related", relatedGamesHandler(h))
+
+	for _, target := range []string{"/api/games", "/api/games/upcoming", "/api/games/g1/related"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target+"?include_stale=maybe", nil))
+		var body map[string]any
+		if rec.Code != http.StatusBadRequest || json.Unmarshal(rec.Body.Bytes(), &body) != nil {
+			t.Errorf("%s?include_stale=maybe = %d %s, want a single 400 error body", target, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestRelatedGamesHidesStale(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	var games []Game
+	for _, id := range []string{"g1", "g2", "stale"} {
+		games = append(games, fixtureGame(id, "soccer", "pre", "0", time.Hour))
+	}
+	if err := upsertGames(ctx, pool, games); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, "UPDATE games SET missed_syncs = $1 WHERE game_id = 'stale'", missingSyncsLimit()); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games/:id/related", relatedGamesHandler(newDBHandle("DB", "", pool)))
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{"", 1},
+		{"?include_stale=true", 2},
+	} {
+		var resp struct {
+			Games []GameView `json:"games"`
+		}
+		getJSON(t, r, "/api/games/g1/related"+tt.query, &resp)
+		if len(resp.Games) != tt.want {
+			t.Errorf("related%s = %d games, want %d", tt.query, len(resp.Games), tt.want)
+		}
+	}
+}
+
+func TestSuspendedGamesValidatesSport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games/suspended", suspendedGamesHandler(newDBHandle("DB", "", nil)))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/games/suspended?sport=curling", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("sport=curling = %d %s, want 400", rec.Code, rec.Body)
+	}
+}
+
+func TestSuspendedGamesSportFilter(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	games := []Game{
+		fixtureGame("s1", "soccer", "live", "1", time.Hour),
+		fixtureGame("t1", "tennis", "live", "1", time.Hour),
+	}
+	if err := upsertGames(ctx, pool, games); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range games {
+		odd := LiveOdd{
+			GameID: g.GameID, Sport: g.Sport, Bookmaker: "bet365", MarketID: "m1", MarketName: "Match Winner",
+			SelectionID: "x", SelectionName: "Home", PriceDec: "2", PriceFrac: "1/1", IsSuspended: true,
+		}
+		if err := insertLiveOdds(ctx, pool, []LiveOdd{odd}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games/suspended", suspendedGamesHandler(newDBHandle("DB", "", pool)))
+	for query, want := range map[string]int{"": 2, "?sport=tennis": 1, "?sport=soccer,tennis": 2} {
+		var resp struct {
+			Games []SuspendedGameView `json:"games"`
+		}
+		getJSON(t, r, "/api/games/suspended"+query, &resp)
+		if len(resp.Games) != want {
+			t.Errorf("suspended%s = %d games, want %d", query, len(resp.Games), want)
+		}
+	}
+}
+
+func TestUpcomingGamesLimitAndOdds(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	games := []Game{
+		fixtureGame("g1", "soccer", "pre", "0", 10*time.Minute),
+		fixtureGame("g2", "tennis", "pre", "0", 15*time.Minute),
+		fixtureGame("g3", "soccer", "pre", "0", 20*time.Minute),
+	}
+	if err := upsertGames(ctx, pool, games); err != nil {
+		t.Fatal(err)
+	}
+	odds := []LiveOdd{
+		{GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", MarketName: "Fulltime Result",
+			SelectionID: "s1", SelectionName: "Home g1", PriceDec: "1.5", PriceFrac: "1/2"},
+		{GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m2", MarketName: "Both Teams to Score",
+			SelectionID: "s2", SelectionName: "Yes", PriceDec: "1.8", PriceFrac: "4/5"},
+		{GameID: "g2", Sport: "tennis", Bookmaker: "bet365", MarketID: "m3", MarketName: "To Win Match",
+			SelectionID: "s3", SelectionName: "Home g2", PriceDec: "2", PriceFrac: "1/1"},
+	}
+	for _, o := range odds {
+		if err := insertLiveOdds(ctx, pool, []LiveOdd{o}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games/upcoming", upcomingGamesHandler(newDBHandle("DB", "", pool)))
+
+	var resp struct {
+		Games []UpcomingGameView `json:"games"`
+	}
+	getJSON(t, r, "/api/games/upcoming?limit=2", &resp)
+	if len(resp.Games) != 2 || resp.Games[0].GameID != "g1" || resp.Games[1].GameID != "g2" {
+		t.Fatalf("upcoming?limit=2 = %+v, want g1 and g2", resp.Games)
+	}
+	// у каждого матча — только исходы основного рынка своего спорта
+	for i, want := range []string{"Home g1", "Home g2"} {
+		g := resp.Games[i]
+		if len(g.Odds) != 1 || g.OddsError || g.Odds[0].SelectionName != want {
+			t.Fatalf("%s odds = %+v (error %v), want only %s", g.GameID, g.Odds, g.OddsError, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/games/upcoming?limit=1001", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("limit=1001 = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- API SCHEMA ---
+// GET /api/schema — описание ответов API, собранное рефлексией из view-структур,
+// поэтому новое поле в структуре сразу появляется и здесь.
+
+type FieldDoc struct {
+	Name     string     `json:"name,omitempty"`
+	Type     string     `json:"type"` // string / integer / number / boolean / datetime / object / array
+	Nullable bool       `json:"nullable,omitempty"`
+	Optional bool       `json:"optional,omitempty"` // omitempty: поля может не быть в ответе
+	Comment  string     `json:"comment,omitempty"`
+	Items    *FieldDoc  `json:"items,omitempty"`  // для array
+	Fields   []FieldDoc `json:"fields,omitempty"` // для object
+}
+
+type EndpointDoc struct {
+	Method  string     `json:"method"`
+	Path    string     `json:"path"`
+	ListKey string     `json:"list_key,omitempty"` // ключ списка в v1; в v2 список всегда в data
+	Fields  []FieldDoc `json:"fields"`             // поля элемента списка или объекта ответа
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func describeType(t reflect.Type) FieldDoc {
+	var d FieldDoc
+	if t.Kind() == reflect.Pointer {
+		d = describeType(t.Elem())
+		d.Nullable = true
+		return d
+	}
+	switch {
+	case t == timeType:
+		d.Type = "datetime"
+	case t.Kind() == reflect.String:
+		d.Type = "string"
+	case t.Kind() == reflect.Bool:
+		d.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		d.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		d.Type = "number"
+	case t.Kind() == reflect.Slice:
+		items := describeType(t.Elem())
+		d.Type, d.Items, d.Nullable = "array", &items, true
+	case t.Kind() == reflect.Map:
+		items := describeType(t.Elem())
+		d.Type, d.Items = "object", &items
+		d.Comment = "keys are dynamic"
+	case t.Kind() == reflect.Struct:
+		d.Type, d.Fields = "object", describeFields(t)
+	default:
+		d.Type = t.Kind().String()
+	}
+	return d
+}
+
+func describeFields(t reflect.Type) []FieldDoc {
+	var out []FieldDoc
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			// встроенная структура: encoding/json поднимает её поля на уровень выше
+			out = append(out, describeFields(f.Type)...)
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		d := describeType(f.Type)
+		d.Name = name
+		d.Optional = slices.Contains(strings.Split(opts, ","), "omitempty")
+		out = append(out, d)
+	}
+	return out
+}
+
+func fieldsOf(v any) []FieldDoc {
+	return describeFields(reflect.TypeOf(v))
+}
+
+// gameListItemFields — элемент /api/games: поля GameView из gameListFields плюс облегчённые odds.
+func gameListItemFields() []FieldDoc {
+	var out []FieldDoc
+	for _, f := range fieldsOf(GameView{}) {
+		if slices.Contains(gameListFields, f.Name) {
+			out = append(out, f)
+		}
+	}
+	odds := describeType(reflect.TypeOf([]ListOddView{}))
+	odds.Name, odds.Optional = "odds", true
+	oddsError := FieldDoc{Name: "odds_error", Type: "boolean", Optional: true, Comment: "odds query failed; odds is empty"}
+	marketCount := FieldDoc{Name: "market_count", Type: "integer", Optional: true, Comment: "include=market_count: distinct non-closed markets"}
+	return append(out, odds, oddsError, marketCount)
+}
+
+func apiSchema() []EndpointDoc {
+	markets := describeType(reflect.TypeOf(map[string][]OddView{}))
+	markets.Name, markets.Comment = "markets", "keyed by market_key"
+	game := describeType(reflect.TypeOf(GameView{}))
+	game.Name = "game"
+	favorite := describeType(reflect.TypeOf(&FavoriteView{}))
+	favorite.Name, favorite.Comment = "favorite", "null when the sport has no primary market or it has no open prices"
+
+	return []EndpointDoc{
+		{Method: "GET", Path: "/api/games", ListKey: "games", Fields: gameListItemFields()},
+		{Method: "GET", Path: "/api/games/upcoming", ListKey: "games", Fields: fieldsOf(UpcomingGameView{})},
+		{Method: "GET", Path: "/api/games/suspended", ListKey: "games", Fields: fieldsOf(SuspendedGameView{})},
+		{Method: "GET", Path: "/api/live", ListKey: "games", Fields: fieldsOf(LiveGameView{})},
+		{Method: "GET", Path: "/api/games/:id", Fields: []FieldDoc{game, markets}},
+		{Method: "GET", Path: "/api/games/:id/movement", ListKey: "movement", Fields: fieldsOf(MovementView{})},
+		{Method: "GET", Path: "/api/games/:id/smoothed", ListKey: "smoothed", Fields: fieldsOf(SmoothedView{})},
+		{Method: "GET", Path: "/api/games/:id/snapshot-diff", ListKey: "selections", Fields: fieldsOf(SnapshotDiffView{})},
+		{Method: "GET", Path: "/api/games/:id/related", ListKey: "games", Fields: fieldsOf(GameView{})},
+		{Method: "GET", Path: "/api/games/:id/compare", ListKey: "selections", Fields: fieldsOf(CompareView{})},
+		{Method: "GET", Path: "/api/games/:id/favorite", Fields: []FieldDoc{
+			{Name: "game_id", Type: "string"},
+			{Name: "primary_markets", Type: "array", Items: &FieldDoc{Type: "string"}},
+			favorite,
+		}},
+		{Method: "GET", Path: "/api/games/:id/events", ListKey: "events", Fields: fieldsOf(PriceEventView{})},
+		{Method: "GET", Path: "/api/games/:id/incidents", ListKey: "incidents", Fields: fieldsOf(IncidentView{})},
+		{Method: "GET", Path: "/api/markets", ListKey: "markets", Fields: fieldsOf(MarketView{})},
+		{Method: "GET", Path: "/api/odds", ListKey: "odds", Fields: fieldsOf(OddsRowView{})},
+		{Method: "GET", Path: "/api/sync-runs", ListKey: "runs", Fields: fieldsOf(SyncRunView{})},
+	}
+}
+
+func apiSchemaHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"endpoints":     apiSchema(),
+			"time_statuses": timeStatusDocs(),
+			"envelope": gin.H{
+				"v1": "list under list_key, extra fields at top level",
+				"v2": "X-API-Version: 2 or ?api_version=2 → {data: [...], meta: {count, limit, offset, generated_at, ...}}",
+			},
+		})
+	}
+}
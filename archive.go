@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- RESPONSE ARCHIVE ---
+// ARCHIVE_RESPONSES=true — каждый ответ апстрима целиком пишется в ARCHIVE_DIR (./archive)
+// отдельным JSON-файлом вместе с task/sport/game_id. Нужен, чтобы разобраться с поломками
+// парсинга и прогнать тот же ответ через текущий парсер: GET /admin/replay?file=...
+// Файлы не ротируются — чистить каталог нужно снаружи.
+
+type archiveMeta struct {
+	Task      string    `json:"task"`
+	Sport     string    `json:"sport,omitempty"`
+	GameID    string    `json:"game_id,omitempty"`
+	Bookmaker string    `json:"bookmaker,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type archivedResponse struct {
+	archiveMeta
+	Body json.RawMessage `json:"body"`
+}
+
+func archiveDir() string {
+	return getEnv("ARCHIVE_DIR", "archive")
+}
+
+// upstreamFetch читает ответ апстрима целиком и при включённом архиве сохраняет его.
+// Ошибка записи архива только логируется — синк из-за неё не падает.
+func upstreamFetch(ctx context.Context, rawURL string, meta archiveMeta) ([]byte, error) {
+	res, err := upstreamGet(ctx, rawURL)
+	if err != nil {
+		if upstreamErr, ok := err.(*UpstreamError); ok {
+			upstreamErr.Task = meta.Task
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, &UpstreamError{Task: meta.Task, Status: res.StatusCode, Err: err}
+	}
+
+	if getEnvBool("ARCHIVE_RESPONSES", false) {
+		meta.FetchedAt = time.Now().UTC()
+		if err := archiveResponse(meta, body); err != nil {
+			logf(ctx, "❌ Archive %s response error: %v", meta.Task, err)
+		}
+	}
+	return body, nil
+}
+
+func archiveResponse(meta archiveMeta, body []byte) error {
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(body)) // не-JSON (HTML ошибки и т.п.) сохраняем строкой
+	}
+	data, err := json.Marshal(archivedResponse{archiveMeta: meta, Body: body})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(archiveDir(), 0o755); err != nil {
+		return err
+	}
+	parts := []string{meta.FetchedAt.Format("20060102T150405.000000000Z"), meta.Task}
+	for _, p := range []string{meta.Sport, meta.GameID, meta.Bookmaker} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, strings.Join(parts, "_")) + ".json"
+	return os.WriteFile(filepath.Join(archiveDir(), name), data, 0o644)
+}
+
+// GET /admin/archive?limit=50 — последние архивные ответы, новые первыми.
+func archiveListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := queryInt(c, "limit", 50, 1, 1000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		entries, err := os.ReadDir(archiveDir())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			serverError(c, err)
+			return
+		}
+		files := []string{}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				files = append(files, e.Name())
+			}
+		}
+		// имя начинается с UTC-времени, так что сортировка по имени — по времени
+		slices.Sort(files)
+		slices.Reverse(files)
+		c.JSON(http.StatusOK, gin.H{"files": files[:min(limit, len(files))], "total": len(files)})
+	}
+}
+
+// GET /admin/replay?file=<имя> — прогнать архивный ответ через текущий парсер, ничего не записывая.
+func replayHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("file")
+		if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".json") {
+			badRequest(c, fmt.Errorf("file must be an archive file name, got %q", name))
+			return
+		}
+		data, err := os.ReadFile(filepath.Join(archiveDir(), name))
+		if errors.Is(err, os.ErrNotExist) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "archive file not found"})
+			return
+		}
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		var ar archivedResponse
+		if err := json.Unmarshal(data, &ar); err != nil {
+			serverError(c, fmt.Errorf("archive file %s: %w", name, err))
+			return
+		}
+
+		resp := gin.H{"meta": ar.archiveMeta}
+		switch ar.Task {
+		case "pre", "live":
+			parse := parsePreGames
+			if ar.Task == "live" {
+				parse = parseLiveGames
+			}
+			games, err := parse(ar.Body, ar.Sport)
+			if err != nil {
+				resp["error"] = err.Error()
+				break
+			}
+			resp["games"] = games
+			resp["count"] = len(games)
+		case "liveodds":
+			var apiResp APIResponse
+			if err := decodeJSON(ar.Body, &apiResp); err != nil {
+				resp["error"] = err.Error()
+				break
+			}
+			odds, orphans := parseLiveOdds(apiResp, ar.GameID, ar.Sport, ar.Bookmaker)
+			shared, dups := selectionIDAnomalies(odds)
+			resp["odds"] = odds
+			resp["incidents"] = parseIncidents(apiResp, ar.GameID)
+			resp["orphan_selections"] = orphans
+			resp["shared_selection_ids"] = shared
+			resp["duplicate_selections"] = dups
+		default:
+			resp["error"] = "unknown task " + ar.Task
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// --- CIRCUIT BREAKER ---
+// После threshold подряд неудачных запросов к апстриму цепь размыкается на cooldown:
+// запросы падают сразу, без похода в сеть. Затем пропускается один пробный запрос
+// (half-open): успех замыкает цепь, неудача снова размыкает.
+
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+var errCircuitOpen = errors.New("upstream circuit breaker is open")
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+type breakerStats struct {
+	State     string     `json:"state"`
+	Failures  int        `json:"consecutive_failures"`
+	Threshold int        `json:"threshold"`
+	Cooldown  string     `json:"cooldown"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+}
+
+var upstreamBreaker = newCircuitBreaker(5, 30*time.Second)
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: breakerClosed}
+}
+
+// allow сообщает, можно ли сейчас идти в апстрим.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return errCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return nil
+	case breakerHalfOpen:
+		// Пробный запрос уже в полёте — остальные ждут его результата
+		if b.probing {
+			return errCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// release отпускает пробный запрос, не меняя состояния: ответ был, но ничего не сказал
+// о здоровье апстрима (401/403, ошибка до отправки). Иначе probing остался бы true навсегда
+// и half-open цепь отклоняла бы все запросы до рестарта.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) stats() breakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := breakerStats{
+		State:     b.state,
+		Failures:  b.failures,
+		Threshold: b.threshold,
+		Cooldown:  b.cooldown.String(),
+	}
+	if b.state != breakerClosed {
+		t := b.openedAt
+		s.OpenedAt = &t
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// halfOpenBreaker — breaker, разомкнутый одной неудачей, с нулевым cooldown:
+// следующий allow() сразу переводит его в half-open.
+func halfOpenBreaker(t *testing.T) *circuitBreaker {
+	t.Helper()
+	b := newCircuitBreaker(1, 0)
+	b.failure()
+	if got := b.stats().State; got != breakerOpen {
+		t.Fatalf("state after failure = %s, want %s", got, breakerOpen)
+	}
+	return b
+}
+
+func TestBreakerHalfOpenAllowsOneProbe(t *testing.T) {
+	b := halfOpenBreaker(t)
+	if err := b.allow(); err != nil {
+		t.Fatalf("first allow in half-open: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("second allow while probing = %v, want errCircuitOpen", err)
+	}
+	b.success()
+	if got := b.stats().State; got != breakerClosed {
+		t.Fatalf("state after success = %s, want %s", got, breakerClosed)
+	}
+}
+
+func TestBreakerReleaseKeepsStateAndFreesProbe(t *testing.T) {
+	b := halfOpenBreaker(t)
+	if err := b.allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.release()
+	if got := b.stats().State; got != breakerHalfOpen {
+		t.Fatalf("state after release = %s, want %s", got, breakerHalfOpen)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow after release: %v", err)
+	}
+}
+
+func TestUpstreamGetForbiddenReleasesProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	saved := upstreamBreaker
+	defer func() { upstreamBreaker = saved }()
+	upstreamBreaker = halfOpenBreaker(t)
+
+	_, err := upstreamGet(context.Background(), srv.URL)
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.Status != http.StatusForbidden {
+		t.Fatalf("upstreamGet error = %v, want UpstreamError with status 403", err)
+	}
+	if err := upstreamBreaker.allow(); err != nil {
+		t.Fatalf("allow after 403 probe = %v, want nil", err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- CACHE ---
+
+// ttlCache — простой in-memory кэш ответов с фиксированным временем жизни.
+type ttlCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]cacheItem
+}
+
+type cacheItem struct {
+	value   any
+	expires time.Time
+}
+
+// caches — все именованные кэши процесса, чтобы их можно было сбросить разом.
+// Заполняется при инициализации пакета, дальше только читается.
+var caches = map[string]*ttlCache{}
+
+func newTTLCache(name string, ttl time.Duration) *ttlCache {
+	c := &ttlCache{ttl: ttl, items: map[string]cacheItem{}}
+	caches[name] = c
+	return c
+}
+
+func (c *ttlCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, ok := c.items[key]
+	if !ok || time.Now().After(it.expires) {
+		delete(c.items, key)
+		return nil, false
+	}
+	return it.value, true
+}
+
+func (c *ttlCache) set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = cacheItem{value: value, expires: time.Now().Add(c.ttl)}
+}
+
+// flush очищает кэш и возвращает число выброшенных записей.
+func (c *ttlCache) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.items)
+	c.items = map[string]cacheItem{}
+	return n
+}
+
+// cacheFlushHandler — POST /admin/cache/flush: сбрасывает все in-memory кэши,
+// например после ручной правки данных в базе.
+func cacheFlushHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cleared := map[string]int{}
+		for name, cache := range caches {
+			cleared[name] = cache.flush()
+		}
+		logf(c.Request.Context(), "🧹 Caches flushed: %v", cleared)
+		c.JSON(http.StatusOK, gin.H{"cleared": cleared})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- COMPACTION ---
+// price_events растёт с каждым изменением цены. Для записей старше COMPACT_AFTER (24h)
+// оставляем одну на исход за COMPACT_BUCKET (1m) — последнюю, а её old_price/delta
+// пересчитываем от первой записи корзины, чтобы цепочка old -> new не рвалась.
+// Запускается каждые COMPACT_INTERVAL (0 — выключено, по умолчанию).
+
+func compactLoop(ctx context.Context, h *dbHandle) {
+	every := getEnvDuration("COMPACT_INTERVAL", 0)
+	if every <= 0 {
+		return
+	}
+	after := getEnvDuration("COMPACT_AFTER", 24*time.Hour)
+	bucket := max(getEnvDuration("COMPACT_BUCKET", time.Minute), time.Second)
+	log.Printf("🗜️ Compaction every %s: price_events older than %s down to one per %s", every, after, bucket)
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if pause.active() {
+			continue
+		}
+		done, err := work.begin()
+		if err != nil {
+			return
+		}
+		start := time.Now()
+		deleted, err := compactPriceEvents(context.WithoutCancel(ctx), h.Pool(), after, bucket)
+		done()
+		state.recordCompaction(int(deleted), err)
+		if err != nil {
+			log.Printf("❌ Compaction error: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("🗜️ Compacted price_events: %d rows removed in %s", deleted, time.Since(start).Round(time.Millisecond))
+		}
+	}
+}
+
+// compactPriceEvents возвращает число удалённых строк.
+func compactPriceEvents(ctx context.Context, pool *pgxpool.Pool, after, bucket time.Duration) (int64, error) {
+	var deleted int64
+	err := withTx(ctx, pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			WITH ranked AS (
+				SELECT id,
+				       first_value(old_price) OVER (PARTITION BY game_id, bookmaker, market_id, selection_id, bucket ORDER BY ts, id) AS first_old,
+				       row_number() OVER (PARTITION BY game_id, bookmaker, market_id, selection_id, bucket ORDER BY ts DESC, id DESC) AS rn
+				FROM (
+					SELECT *, floor(extract(epoch FROM ts) / $2) AS bucket
+					FROM price_events
+					WHERE ts < now() - make_interval(secs => $1)
+				) e
+			), kept AS (
+				UPDATE price_events p
+				SET old_price = r.first_old, delta = p.new_price - r.first_old
+				FROM ranked r
+				WHERE p.id = r.id AND r.rn = 1 AND p.old_price <> r.first_old
+			)
+			DELETE FROM price_events p
+			USING ranked r
+			WHERE p.id = r.id AND r.rn > 1`,
+			after.Seconds(), bucket.Seconds())
+		if err != nil {
+			return err
+		}
+		deleted = tag.RowsAffected()
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// --- COMPARE ---
+
+// CompareView — исход со всеми ценами букмекеров, у которых он сейчас открыт.
+// market_id и selection_id у каждого букмекера свои, поэтому исход сопоставляется между
+// букмекерами по compareKey: рынок (market_key, а для нераспознанных "other" — название рынка),
+// линия и название исхода без учёта регистра и лишних пробелов. MarketID, SelectionID и названия —
+// первого по алфавиту букмекера. У букмекеров, которые исход не дают или приостановили, цены в prices нет.
+type CompareView struct {
+	Key           string             `json:"key"`
+	MarketID      string             `json:"market_id"`
+	MarketName    string             `json:"market_name"`
+	MarketKey     string             `json:"market_key"`
+	SelectionID   string             `json:"selection_id"`
+	SelectionName string             `json:"selection_name"`
+	Line          string             `json:"line"`
+	Prices        map[string]float64 `json:"prices"` // bookmaker -> десятичная цена
+	BestBookmaker string             `json:"best_bookmaker"`
+	BestPrice     float64            `json:"best_price"`
+}
+
+// comparePrice — одна строка liveodds для сравнения.
+type comparePrice struct {
+	CompareView
+	bookmaker string
+	price     float64
+}
+
+// compareKey — ключ исхода, общий для всех букмекеров.
+func compareKey(marketKey, marketName, line, selectionName string) string {
+	market := marketKey
+	if market == "" || market == MarketKeyOther {
+		market = MarketKeyOther + ":" + normalizeName(marketName)
+	}
+	return market + "|" + strings.TrimSpace(line) + "|" + normalizeName(selectionName)
+}
+
+func normalizeName(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// pivotPrices сворачивает цены в строки по compareKey; строки отсортированы по ключу.
+// prices должны идти по bookmaker — тогда поля строки берутся у первого по алфавиту.
+func pivotPrices(prices []comparePrice) (rows []CompareView, bookmakers []string) {
+	rows, bookmakers = []CompareView{}, []string{}
+	idx := map[string]int{}
+	seenBook := map[string]bool{}
+	for _, p := range prices {
+		if !seenBook[p.bookmaker] {
+			seenBook[p.bookmaker] = true
+			bookmakers = append(bookmakers, p.bookmaker)
+		}
+		key := compareKey(p.MarketKey, p.MarketName, p.Line, p.SelectionName)
+		i, ok := idx[key]
+		if !ok {
+			v := p.CompareView
+			v.Key, v.Prices = key, map[string]float64{}
+			i, idx[key] = len(rows), len(rows)
+			rows = append(rows, v)
+		}
+		cur := &rows[i]
+		// один букмекер дважды под одним ключом (дубль рынка) — берём лучшую цену
+		if old, ok := cur.Prices[p.bookmaker]; !ok || p.price > old {
+			cur.Prices[p.bookmaker] = p.price
+		}
+		if p.price > cur.BestPrice {
+			cur.BestBookmaker, cur.BestPrice = p.bookmaker, p.price
+		}
+	}
+	slices.SortFunc(rows, func(a, b CompareView) int { return strings.Compare(a.Key, b.Key) })
+	return rows, bookmakers
+}
+
+// GET /api/games/:id/compare — цены всех букмекеров по каждому исходу и лучшая из них.
+// В ответе bookmakers — все букмекеры с открытыми ценами по матчу, чтобы UI построил колонки.
+// primary_odds_only=true — только основной рынок матча (PRIMARY_MARKET_<SPORT>), как в /api/games.
+func gameCompareHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		id := c.Param("id")
+		primaryOnly, err := queryBool(c, "primary_odds_only", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		var primary []string
+		if primaryOnly {
+			sport, err := getGameSport(c.Request.Context(), db, id)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				serverError(c, err)
+				return
+			}
+			primary = primaryMarkets(sport)
+		}
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT market_id, market_name, market_key, selection_id, selection_name, line, bookmaker, price_dec::float8
+			FROM liveodds
+			WHERE game_id = $1 AND status = 'open' AND price_dec IS NOT NULL
+			ORDER BY bookmaker, market_id, selection_id`, id)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		prices, err := scanAll(rows, func(r rowScanner) (comparePrice, error) {
+			var p comparePrice
+			err := r.Scan(&p.MarketID, &p.MarketName, &p.MarketKey, &p.SelectionID, &p.SelectionName, &p.Line, &p.bookmaker, &p.price)
+			return p, err
+		})
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		if primaryOnly {
+			prices = slices.DeleteFunc(prices, func(p comparePrice) bool {
+				return !isPrimaryMarket(primary, p.MarketID, p.MarketName)
+			})
+		}
+
+		out, bookmakers := pivotPrices(prices)
+		respondList(c, "selections", out, len(out), 0, 0, gin.H{"game_id": id, "bookmakers": bookmakers})
+	}
+}
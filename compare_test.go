@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPivotPricesAcrossBookmakers(t *testing.T) {
+	price := func(book, marketID, marketName, marketKey, selID, selName, line string, p float64) comparePrice {
+		return comparePrice{
+			CompareView: CompareView{
+				MarketID: marketID, MarketName: marketName, MarketKey: marketKey,
+				SelectionID: selID, SelectionName: selName, Line: line,
+			},
+			bookmaker: book, price: p,
+		}
+	}
+	// отсортировано по bookmaker, как в запросе; ID рынков и исходов у букмекеров разные
+	prices := []comparePrice{
+		price("bet365", "40", "Fulltime Result", MarketKey1X2, "101", "Arsenal", "", 2.1),
+		price("bet365", "40", "Fulltime Result", MarketKey1X2, "102", "Draw", "", 3.4),
+		price("bet365", "421", "Match Goals", MarketKeyTotals, "201", "Over", "2.5", 1.9),
+		price("bet365", "421", "Match Goals", MarketKeyTotals, "202", "Over", "3.5", 2.8),
+		price("bet365", "900", "Corners", MarketKeyOther, "301", "Over", "10.5", 1.8),
+		price("pinnacle", "1x2", "Match Result", MarketKey1X2, "a", " arsenal ", "", 2.2),
+		price("pinnacle", "tot", "Total Goals", MarketKeyTotals, "b", "OVER", "2.5", 1.95),
+		price("pinnacle", "901", "Cards", MarketKeyOther, "c", "Over", "10.5", 1.7),
+	}
+	rows, books := pivotPrices(prices)
+
+	if !slices.Equal(books, []string{"bet365", "pinnacle"}) {
+		t.Errorf("bookmakers = %v", books)
+	}
+	type row struct {
+		key   string
+		books int
+		best  string
+	}
+	var got []row
+	for _, r := range rows {
+		got = append(got, row{r.Key, len(r.Prices), r.BestBookmaker})
+	}
+	want := []row{
+		{"1x2||arsenal", 2, "pinnacle"},
+		{"1x2||draw", 1, "bet365"}, // только у одного букмекера
+		{"other:cards|10.5|over", 1, "pinnacle"},
+		{"other:corners|10.5|over", 1, "bet365"}, // "other" разных рынков не смешиваются
+		{"totals|2.5|over", 2, "pinnacle"},
+		{"totals|3.5|over", 1, "bet365"}, // другая линия — другой исход
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("rows:\n got  %v\n want %v", got, want)
+	}
+	if r := rows[0]; r.MarketID != "40" || r.SelectionID != "101" || r.SelectionName != "Arsenal" || r.Prices["bet365"] != 2.1 || r.Prices["pinnacle"] != 2.2 {
+		t.Errorf("first row = %+v, want bet365 IDs and both prices", r)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// --- CONFIG ---
+// Источники по приоритету: переменные окружения (в т.ч. из .env) > CONFIG_FILE > значения по умолчанию.
+// CONFIG_FILE — YAML или JSON с теми же ключами, что и env, в любом регистре:
+//
+//	sports: [soccer, tennis]
+//	bookmakers: [bet365, pinnacle]
+//	slow_threshold_ms: 1500
+//
+// Значения из файла выставляются в окружение только для незаданных переменных,
+// поэтому getEnv* по всему коду видят уже слитый конфиг.
+
+type Config struct {
+	DatabaseURL       string
+	Port              string
+	BindAddr          string // BIND_ADDR: адрес интерфейса (127.0.0.1 за прокси); пусто — все интерфейсы
+	Sports            []string
+	Bookmakers        []string
+	OddsDecimalPlaces int
+	BatchChunkSize    int
+	BreakerThreshold  int
+	BreakerCooldown   time.Duration
+	PriceMin          float64
+	PriceMax          float64
+	PriceEpsilon      float64
+	OddsTouchInterval time.Duration
+	StoreRaw          bool
+
+	MaintenanceWindows []maintenanceWindow
+	MaintenanceTZ      *time.Location
+
+	ReadOnlyDatabaseURL string // реплика для /api; пусто — читаем из DATABASE_URL
+
+	ActiveTimeStatuses []string // см. timestatus.go
+	OddsRounding       string   // half_up / bankers, см. roundPrice
+
+	TxRetryAttempts int // см. txretry.go
+	TxRetryBackoff  time.Duration
+	SyncConcurrency int // параллельные фиды в fetchAllGames
+
+	NewSelectionWindow time.Duration // см. newselections.go
+
+	DBHealthInterval time.Duration // DB_HEALTH_INTERVAL_SEC, см. healthLoop
+}
+
+func loadConfig() (Config, error) {
+	if path := getEnv("CONFIG_FILE", ""); path != "" {
+		if err := applyConfigFile(path); err != nil {
+			return Config{}, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	cfg := Config{
+		DatabaseURL:       getEnv("DATABASE_URL", ""),
+		Port:              getEnv("PORT", "9090"),
+		BindAddr:          getEnv("BIND_ADDR", ""),
+		Sports:            getEnvList("SPORTS", []string{"soccer", "tennis"}),
+		Bookmakers:        getEnvList("BOOKMAKERS", []string{"bet365"}),
+		OddsDecimalPlaces: getEnvInt("ODDS_DECIMAL_PLACES", 3),
+		BatchChunkSize:    getEnvInt("BATCH_CHUNK_SIZE", 500),
+		BreakerThreshold:  getEnvInt("BREAKER_THRESHOLD", 5),
+		BreakerCooldown:   time.Duration(getEnvInt("BREAKER_COOLDOWN_SEC", 30)) * time.Second,
+		PriceMin:          getEnvFloat("PRICE_MIN", 1.01),
+		PriceMax:          getEnvFloat("PRICE_MAX", 1000),
+		PriceEpsilon:      getEnvFloat("PRICE_EPSILON", 0),
+		OddsTouchInterval: getEnvDuration("ODDS_TOUCH_INTERVAL", 5*time.Minute),
+		StoreRaw:          getEnvBool("STORE_RAW", true),
+	}
+
+	cfg.ReadOnlyDatabaseURL = getEnv("READONLY_DATABASE_URL", "")
+	cfg.ActiveTimeStatuses = getEnvList("ACTIVE_TIME_STATUSES", defaultActiveTimeStatuses)
+	cfg.OddsRounding = getEnv("ODDS_ROUNDING", RoundHalfUp)
+	cfg.TxRetryAttempts = getEnvInt("DB_RETRY_ATTEMPTS", 3)
+	cfg.TxRetryBackoff = getEnvDuration("DB_RETRY_BACKOFF", 100*time.Millisecond)
+	cfg.SyncConcurrency = getEnvInt("SYNC_CONCURRENCY", 4)
+	cfg.NewSelectionWindow = getEnvDuration("NEW_SELECTION_WINDOW", 5*time.Minute)
+	cfg.DBHealthInterval = time.Duration(getEnvInt("DB_HEALTH_INTERVAL_SEC", 10)) * time.Second
+
+	var errs []error
+	windows, err := parseMaintenanceWindows(getEnvList("MAINTENANCE_WINDOWS", nil))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("MAINTENANCE_WINDOWS: %w", err))
+	}
+	cfg.MaintenanceWindows = windows
+	cfg.MaintenanceTZ, err = time.LoadLocation(getEnv("MAINTENANCE_TZ", "UTC"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("MAINTENANCE_TZ: %w", err))
+	}
+
+	return cfg, errors.Join(append(errs, cfg.validate())...)
+}
+
+func (c Config) validate() error {
+	var errs []error
+	if c.DatabaseURL == "" {
+		errs = append(errs, errors.New("DATABASE_URL is required"))
+	}
+	if p, err := strconv.Atoi(c.Port); err != nil || p <= 0 || p > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a valid port, got %q", c.Port))
+	}
+	if c.BindAddr != "" && net.ParseIP(c.BindAddr) == nil && c.BindAddr != "localhost" {
+		errs = append(errs, fmt.Errorf("BIND_ADDR must be an IP address, got %q", c.BindAddr))
+	}
+	if len(c.Sports) == 0 {
+		errs = append(errs, errors.New("SPORTS must not be empty"))
+	}
+	if len(c.Bookmakers) == 0 {
+		errs = append(errs, errors.New("BOOKMAKERS must not be empty"))
+	}
+	if c.OddsDecimalPlaces < -1 || c.OddsDecimalPlaces > 10 {
+		errs = append(errs, fmt.Errorf("ODDS_DECIMAL_PLACES must be between -1 and 10, got %d", c.OddsDecimalPlaces))
+	}
+	if c.BatchChunkSize <= 0 {
+		errs = append(errs, fmt.Errorf("BATCH_CHUNK_SIZE must be positive, got %d", c.BatchChunkSize))
+	}
+	if c.BreakerThreshold <= 0 {
+		errs = append(errs, fmt.Errorf("BREAKER_THRESHOLD must be positive, got %d", c.BreakerThreshold))
+	}
+	if c.PriceMin < 1 || c.PriceMax <= c.PriceMin {
+		errs = append(errs, fmt.Errorf("PRICE_MIN/PRICE_MAX must satisfy 1 <= min < max, got %g..%g", c.PriceMin, c.PriceMax))
+	}
+	if err := validateActiveTimeStatuses(c.ActiveTimeStatuses); err != nil {
+		errs = append(errs, fmt.Errorf("ACTIVE_TIME_STATUSES: %w", err))
+	}
+	if c.OddsRounding != RoundHalfUp && c.OddsRounding != RoundBankers {
+		errs = append(errs, fmt.Errorf("ODDS_ROUNDING must be %s or %s, got %q", RoundHalfUp, RoundBankers, c.OddsRounding))
+	}
+	if c.PriceEpsilon < 0 {
+		errs = append(errs, fmt.Errorf("PRICE_EPSILON must not be negative, got %g", c.PriceEpsilon))
+	}
+	if c.OddsTouchInterval <= 0 || c.OddsTouchInterval >= 24*time.Hour {
+		// строки с fetched_at старше суток удаляются при каждой вставке
+		errs = append(errs, fmt.Errorf("ODDS_TOUCH_INTERVAL must be between 0 and 24h, got %s", c.OddsTouchInterval))
+	}
+	if c.TxRetryAttempts < 1 || c.TxRetryAttempts > 10 {
+		errs = append(errs, fmt.Errorf("DB_RETRY_ATTEMPTS must be between 1 and 10, got %d", c.TxRetryAttempts))
+	}
+	if c.TxRetryBackoff < 0 || c.TxRetryBackoff > 10*time.Second {
+		errs = append(errs, fmt.Errorf("DB_RETRY_BACKOFF must be between 0 and 10s, got %s", c.TxRetryBackoff))
+	}
+	if c.SyncConcurrency < 1 || c.SyncConcurrency > 32 {
+		errs = append(errs, fmt.Errorf("SYNC_CONCURRENCY must be between 1 and 32, got %d", c.SyncConcurrency))
+	}
+	if c.NewSelectionWindow < 0 || c.NewSelectionWindow >= 24*time.Hour {
+		errs = append(errs, fmt.Errorf("NEW_SELECTION_WINDOW must be between 0 and 24h, got %s", c.NewSelectionWindow))
+	}
+	if c.DBHealthInterval <= 0 {
+		// time.NewTicker паникует на неположительном интервале
+		errs = append(errs, fmt.Errorf("DB_HEALTH_INTERVAL_SEC must be positive, got %d", c.DBHealthInterval/time.Second))
+	}
+	return errors.Join(errs...)
+}
+
+// applyConfigFile выставляет значения из файла в окружение, не перетирая уже заданные.
+// JSON — подмножество YAML, поэтому один парсер читает оба формата.
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		key := strings.ToUpper(k)
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		val, err := configValue(raw[k])
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		os.Setenv(key, val)
+	}
+	return nil
+}
+
+func configValue(v any) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case []any:
+		parts := make([]string, len(t))
+		for i, item := range t {
+			s, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	case map[string]any:
+		return "", errors.New("nested objects are not supported")
+	default:
+		return fmt.Sprint(t), nil
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDBHealthIntervalConfig(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/jonathan")
+	for _, tt := range []struct {
+		env   string
+		want  time.Duration
+		valid bool
+	}{
+		{"", 10 * time.Second, true},
+		{"30", 30 * time.Second, true},
+		{"0", 0, false},
+		{"-5", -5 * time.Second, false},
+	} {
+		t.Setenv("DB_HEALTH_INTERVAL_SEC", tt.env)
+		cfg, err := loadConfig()
+		if cfg.DBHealthInterval != tt.want || (err == nil) != tt.valid {
+			t.Errorf("DB_HEALTH_INTERVAL_SEC=%q: interval=%s err=%v, want %s valid=%v", tt.env, cfg.DBHealthInterval, err, tt.want, tt.valid)
+		}
+	}
+}
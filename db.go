@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- DB HEALTH ---
+
+// dbHandle держит текущий пул. Health checker пингует его и при затяжном отказе
+// пересоздаёт пул через connectDB, атомарно подменяя указатель.
+// Хендлеры берут пул через Pool() в начале каждого запроса.
+// Основная БД и реплика для чтения (READONLY_DATABASE_URL) — два независимых dbHandle.
+type dbHandle struct {
+	pool atomic.Pointer[pgxpool.Pool]
+	name string // для логов: "DB" / "Read DB"
+	url  string
+
+	mu        sync.RWMutex
+	status    string
+	failures  int
+	lastErr   string
+	lastOK    time.Time
+	reconnect int
+}
+
+func newDBHandle(name, url string, p *pgxpool.Pool) *dbHandle {
+	h := &dbHandle{name: name, url: url, status: "ok", lastOK: time.Now()}
+	h.pool.Store(p)
+	return h
+}
+
+func (h *dbHandle) Pool() *pgxpool.Pool {
+	return h.pool.Load()
+}
+
+func (h *dbHandle) Close() {
+	h.Pool().Close()
+}
+
+// dbHealthInterval — период пинга в healthLoop (DB_HEALTH_INTERVAL_SEC, проверяется в Config.validate).
+var dbHealthInterval = 10 * time.Second
+
+// healthLoop: пинг раз в dbHealthInterval; после DB_RECONNECT_AFTER неудач подряд —
+// попытки пересоздать пул с экспоненциальной паузой (до минуты).
+func (h *dbHandle) healthLoop(ctx context.Context) {
+	interval := dbHealthInterval
+	reconnectAfter := getEnvInt("DB_RECONNECT_AFTER", 3)
+	backoff := interval
+	next := time.Now()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		err := h.Pool().Ping(pingCtx)
+		cancel()
+		if err == nil {
+			h.markOK()
+			backoff = interval
+			continue
+		}
+
+		failures := h.markFailed(err)
+		log.Printf("❌ %s ping failed (%d in a row): %v", h.name, failures, err)
+		if failures < reconnectAfter || time.Now().Before(next) {
+			continue
+		}
+
+		h.setStatus("reconnecting")
+		if err := h.recreate(ctx); err != nil {
+			log.Printf("❌ %s reconnect failed, retry in %s: %v", h.name, backoff, err)
+			next = time.Now().Add(backoff)
+			backoff = min(backoff*2, time.Minute)
+			continue
+		}
+		log.Printf("✅ %s pool recreated", h.name)
+		h.markOK()
+		backoff = interval
+	}
+}
+
+func (h *dbHandle) recreate(ctx context.Context) error {
+	fresh, err := connectDB(h.url)
+	if err != nil {
+		return err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := fresh.Ping(pingCtx); err != nil {
+		fresh.Close()
+		return err
+	}
+	old := h.pool.Swap(fresh)
+	// Старый пул закрываем в фоне: Close ждёт возврата занятых соединений
+	go old.Close()
+
+	h.mu.Lock()
+	h.reconnect++
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *dbHandle) markOK() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = "ok"
+	h.failures = 0
+	h.lastErr = ""
+	h.lastOK = time.Now()
+}
+
+func (h *dbHandle) markFailed(err error) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures++
+	h.lastErr = err.Error()
+	if h.status == "ok" {
+		h.status = "degraded"
+	}
+	return h.failures
+}
+
+func (h *dbHandle) setStatus(s string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = s
+}
+
+func (h *dbHandle) snapshot() gin.H {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return gin.H{
+		"status":     h.status,
+		"failures":   h.failures,
+		"last_error": h.lastErr,
+		"last_ok":    h.lastOK,
+		"reconnects": h.reconnect,
+	}
+}
+
+// GET /readyz — 200, если БД (и реплика для чтения, если она отдельная) доступна, иначе 503.
+func readyzHandler(h, read *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snap := h.snapshot()
+		resp := gin.H{"db": snap}
+		code := 200
+		if snap["status"] != "ok" {
+			code = 503
+		}
+		if read != h {
+			readSnap := read.snapshot()
+			resp["read_db"] = readSnap
+			if readSnap["status"] != "ok" {
+				code = 503
+			}
+		}
+		c.JSON(code, resp)
+	}
+}
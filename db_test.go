@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- TEST DB ---
+// Интеграционные тесты идут против настоящего Postgres из TEST_DATABASE_URL
+// (в CI — сервис postgres, локально — docker compose up db). Без переменной тесты пропускаются.
+// Каждый тест получает свою пустую базу с накатанными миграциями; после теста она удаляется.
+
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect TEST_DATABASE_URL: %v", err)
+	}
+	defer admin.Close(ctx)
+	name := fmt.Sprintf("jonathan_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("create test database: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.Database = name
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("connect test database: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		conn, err := pgx.Connect(context.Background(), dsn)
+		if err != nil {
+			t.Logf("drop test database: %v", err)
+			return
+		}
+		defer conn.Close(context.Background())
+		if _, err := conn.Exec(context.Background(), "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)"); err != nil {
+			t.Logf("drop test database: %v", err)
+		}
+	})
+
+	if err := migrate(pool); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return pool
+}
+
+// getJSON выполняет GET через хендлер и разбирает JSON-ответ.
+func getJSON(t *testing.T, handler http.Handler, target string, out any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d: %s", target, rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("GET %s: decode: %v", target, err)
+	}
+}
+
+func fixtureGame(id, sport, source, status string, startsIn time.Duration) Game {
+	starts := time.Now().Add(startsIn).UTC().Truncate(time.Second)
+	return Game{
+		GameID: id, Sport: sport, Bookmaker: "bet365", Source: source, League: "Premier League",
+		Home: "Home " + id, Away: "Away " + id, TimeStatus: status, StartsAt: &starts,
+	}
+}
+
+func TestSyncedGamesListedByAPI(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	games := []Game{
+		fixtureGame("g1", "soccer", "pre", "0", 2*time.Hour),
+		fixtureGame("g2", "soccer", "live", "1", time.Hour),
+		fixtureGame("g3", "soccer", "pre", "3", time.Hour), // завершён — не в выдаче по умолчанию
+	}
+	if err := upsertGames(ctx, pool, games); err != nil {
+		t.Fatalf("upsertGames: %v", err)
+	}
+	odds := []LiveOdd{{
+		GameID: "g2", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", MarketName: "Fulltime Result",
+		SelectionID: "s1", SelectionName: "Home g2", PriceDec: "1.5", PriceFrac: "1/2",
+		SelectionCount: 3, MarketShape: "1x2", MarketKey: MarketKey1X2, LineType: LineNone,
+	}}
+	if err := insertLiveOdds(ctx, pool, odds); err != nil {
+		t.Fatalf("insertLiveOdds: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games", listGamesHandler(newDBHandle("DB", "", pool)))
+
+	var resp struct {
+		Games []struct {
+			GameID string `json:"game_id"`
+			Odds   []struct {
+				SelectionID string `json:"selection_id"`
+			} `json:"odds"`
+		} `json:"games"`
+		ServerTime time.Time `json:"server_time"`
+	}
+	getJSON(t, r, "/api/games", &resp)
+
+	got := map[string]int{}
+	for _, g := range resp.Games {
+		got[g.GameID] = len(g.Odds)
+	}
+	if len(got) != 2 || got["g2"] != 1 {
+		t.Fatalf("games = %v, want g1 and g2 (g2 with 1 odd)", got)
+	}
+	if _, ok := got["g1"]; !ok {
+		t.Fatalf("games = %v, want g1", got)
+	}
+	if resp.ServerTime.IsZero() {
+		t.Fatal("server_time missing")
+	}
+}
+
+// countRows — число строк в таблице тестовой базы.
+func countRows(t *testing.T, pool *pgxpool.Pool, table string) int {
+	t.Helper()
+	var n int
+	if err := pool.QueryRow(context.Background(), "SELECT count(*) FROM "+table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestUpsertRollsBackOnMidBatchError(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	// NUL в тексте Postgres не принимает — вторая команда батча падает после успешной первой
+	bad := fixtureGame("g2", "soccer", "pre", "0", time.Hour)
+	bad.Home = "bad\x00name"
+	games := []Game{fixtureGame("g1", "soccer", "pre", "0", time.Hour), bad, fixtureGame("g3", "soccer", "pre", "0", time.Hour)}
+	if err := upsertGames(ctx, pool, games); err == nil {
+		t.Fatal("upsertGames with invalid row: want error")
+	}
+	if n := countRows(t, pool, "games"); n != 0 {
+		t.Fatalf("games has %d rows after failed upsert, want 0", n)
+	}
+
+	odds := []LiveOdd{
+		{GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", SelectionID: "s1", SelectionName: "Home", PriceDec: "1.5", PriceFrac: "1/2"},
+		{GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", SelectionID: "s2", SelectionName: "bad\x00name", PriceDec: "2", PriceFrac: "1/1"},
+	}
+	if err := insertLiveOdds(ctx, pool, odds); err == nil {
+		t.Fatal("insertLiveOdds with invalid row: want error")
+	}
+	if n := countRows(t, pool, "liveodds"); n != 0 {
+		t.Fatalf("liveodds has %d rows after failed insert, want 0", n)
+	}
+}
+
+func TestUpsertStalePreDoesNotRevertLive(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	pre := fixtureGame("g1", "soccer", "pre", "0", time.Hour)
+	live := fixtureGame("g1", "soccer", "live", "1", time.Hour)
+	live.Scores = "1-0"
+
+	// в одном синке: pre и live одного матча
+	if err := upsertGames(ctx, pool, []Game{pre, live}); err != nil {
+		t.Fatal(err)
+	}
+	// следующий синк: pre-фид ещё отдаёт матч как не начавшийся
+	if err := upsertGames(ctx, pool, []Game{pre}); err != nil {
+		t.Fatal(err)
+	}
+
+	var source, status, scores string
+	err := pool.QueryRow(ctx, "SELECT source, time_status, scores FROM games WHERE game_id = 'g1'").Scan(&source, &status, &scores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != "live" || status != "1" || scores != "1-0" {
+		t.Fatalf("game after stale pre = %s/%s/%q, want live/1/\"1-0\"", source, status, scores)
+	}
+}
+
+func TestUpsertSpansSeveralBatchChunks(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	saved := batchChunkSize
+	defer func() { batchChunkSize = saved }()
+	batchChunkSize = 2
+
+	var games []Game
+	var odds []LiveOdd
+	for i := range 5 {
+		id := fmt.Sprintf("g%d", i)
+		games = append(games, fixtureGame(id, "soccer", "pre", "0", time.Hour))
+		odds = append(odds, LiveOdd{
+			GameID: "g0", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1",
+			SelectionID: fmt.Sprintf("s%d", i), SelectionName: id, PriceDec: "2", PriceFrac: "1/1",
+		})
+	}
+	if err := upsertGames(ctx, pool, games); err != nil {
+		t.Fatalf("upsertGames: %v", err)
+	}
+	if err := insertLiveOdds(ctx, pool, odds); err != nil {
+		t.Fatalf("insertLiveOdds: %v", err)
+	}
+	if n := countRows(t, pool, "games"); n != 5 {
+		t.Errorf("games = %d rows, want 5", n)
+	}
+	if n := countRows(t, pool, "liveodds"); n != 5 {
+		t.Errorf("liveodds = %d rows, want 5", n)
+	}
+}
+
+func TestUnconvertiblePriceStoredAsNull(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	if err := upsertGames(ctx, pool, []Game{fixtureGame("g1", "soccer", "live", "1", time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	odd := LiveOdd{
+		GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", MarketName: "Fulltime Result",
+		SelectionID: "s1", SelectionName: "Home", PriceFrac: "evens",
+	}
+	if err := insertLiveOdds(ctx, pool, []LiveOdd{odd}); err != nil {
+		t.Fatalf("insertLiveOdds: %v", err)
+	}
+	var dec *string
+	var frac string
+	if err := pool.QueryRow(ctx, "SELECT price_dec::text, price_frac FROM liveodds WHERE selection_id = 's1'").Scan(&dec, &frac); err != nil {
+		t.Fatal(err)
+	}
+	if dec != nil || frac != "evens" {
+		t.Fatalf("price_dec = %v, price_frac = %q; want NULL and \"evens\"", dec, frac)
+	}
+
+	// чтение не спотыкается о NULL: исход отдаётся с price_dec = null
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games", listGamesHandler(newDBHandle("DB", "", pool)))
+	var resp struct {
+		Games []struct {
+			Odds []struct {
+				PriceDec *string `json:"price_dec"`
+			} `json:"odds"`
+		} `json:"games"`
+	}
+	getJSON(t, r, "/api/games", &resp)
+	if len(resp.Games) != 1 || len(resp.Games[0].Odds) != 1 || resp.Games[0].Odds[0].PriceDec != nil {
+		t.Fatalf("/api/games = %+v, want one odd with null price_dec", resp.Games)
+	}
+}
+
+// fetchedAt — различные fetched_at исходов матча, по возрастанию.
+func fetchedAt(t *testing.T, pool *pgxpool.Pool, gameID string) []time.Time {
+	t.Helper()
+	rows, err := pool.Query(context.Background(),
+		"SELECT DISTINCT fetched_at FROM liveodds WHERE game_id = $1 ORDER BY 1", gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestFetchedAtMonotonicAcrossCycles(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	cycle := func(price string) []LiveOdd {
+		var odds []LiveOdd
+		for _, sel := range []string{"s1", "s2", "s3"} {
+			odds = append(odds, LiveOdd{
+				GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1",
+				SelectionID: sel, SelectionName: sel, PriceDec: price, PriceFrac: price,
+			})
+		}
+		return odds
+	}
+
+	if err := insertLiveOdds(ctx, pool, cycle("1.5")); err != nil {
+		t.Fatal(err)
+	}
+	first := fetchedAt(t, pool, "g1")
+	if len(first) != 1 {
+		t.Fatalf("first cycle: %d distinct fetched_at, want 1 (one DB clock reading per cycle)", len(first))
+	}
+
+	if err := insertLiveOdds(ctx, pool, cycle("1.75")); err != nil {
+		t.Fatal(err)
+	}
+	second := fetchedAt(t, pool, "g1")
+	if len(second) != 1 || !second[0].After(first[0]) {
+		t.Fatalf("second cycle fetched_at = %v, want one value after %v", second, first[0])
+	}
+
+	var eventTS time.Time
+	if err := pool.QueryRow(ctx, "SELECT max(ts) FROM price_events WHERE game_id = 'g1'").Scan(&eventTS); err != nil {
+		t.Fatal(err)
+	}
+	if !eventTS.Equal(second[0]) {
+		t.Errorf("price_events ts = %v, want the cycle's fetched_at %v", eventTS, second[0])
+	}
+}
+
+func TestListGamesStableOrderOnTies(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	starts := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	var games []Game
+	for _, id := range []string{"g3", "g1", "g2"} {
+		g := fixtureGame(id, "soccer", "pre", "0", time.Hour)
+		g.StartsAt = &starts
+		games = append(games, g)
+	}
+	if err := upsertGames(ctx, pool, games); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games", listGamesHandler(newDBHandle("DB", "", pool)))
+
+	for _, order := range []string{"asc", "desc"} {
+		var pages []string
+		for offset := range 3 {
+			var resp struct {
+				Games []struct {
+					GameID string `json:"game_id"`
+				} `json:"games"`
+			}
+			getJSON(t, r, fmt.Sprintf("/api/games?include=&order=%s&limit=1&offset=%d", order, offset), &resp)
+			if len(resp.Games) != 1 {
+				t.Fatalf("order=%s offset=%d: %d games, want 1", order, offset, len(resp.Games))
+			}
+			pages = append(pages, resp.Games[0].GameID)
+		}
+		// при равном starts_at порядок задаёт game_id — страницы не повторяются и не теряют матчи
+		if want := []string{"g1", "g2", "g3"}; !slices.Equal(pages, want) {
+			t.Errorf("order=%s: pages = %v, want %v", order, pages, want)
+		}
+	}
+}
+
+func TestInsertLiveOddsSkipsNoopUpdates(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	savedEps, savedTouch := priceEpsilon, oddsTouchInterval
+	defer func() { priceEpsilon, oddsTouchInterval = savedEps, savedTouch }()
+	priceEpsilon, oddsTouchInterval = 0.01, time.Hour
+
+	odd := LiveOdd{
+		GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", MarketName: "Fulltime Result",
+		SelectionID: "s1", SelectionName: "Home", PriceDec: "1.5", PriceFrac: "1/2",
+	}
+	// xmin меняется при каждой перезаписи строки
+	version := func() (xmin, price string) {
+		t.Helper()
+		err := pool.QueryRow(ctx, "SELECT xmin::text, price_dec::text FROM liveodds WHERE selection_id = 's1'").Scan(&xmin, &price)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return xmin, price
+	}
+	write := func(price string) {
+		t.Helper()
+		o := odd
+		o.PriceDec = price
+		if err := insertLiveOdds(ctx, pool, []LiveOdd{o}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("1.5")
+	v1, _ := version()
+
+	write("1.5")   // та же цена
+	write("1.505") // в пределах PRICE_EPSILON
+	if v, p := version(); v != v1 || p != "1.5" {
+		t.Fatalf("row rewritten by unchanged price: xmin %s -> %s, price %s", v1, v, p)
+	}
+	if n := countRows(t, pool, "price_events"); n != 0 {
+		t.Fatalf("price_events = %d, want 0 for changes within epsilon", n)
+	}
+
+	write("1.6")
+	if v, p := version(); v == v1 || p != "1.6" {
+		t.Fatalf("price change not written: xmin %s -> %s, price %s", v1, v, p)
+	}
+	if n := countRows(t, pool, "price_events"); n != 1 {
+		t.Fatalf("price_events = %d, want 1", n)
+	}
+}
+
+func TestListGamesETagChangesWithRemoved(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	since := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if err := upsertGames(ctx, pool, []Game{fixtureGame("g1", "soccer", "pre", "0", time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games", listGamesHandler(newDBHandle("DB", "", pool)))
+
+	target := "/api/games?include=&since=" + since
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first GET = %d, ETag %q", first.Code, etag)
+	}
+
+	// g2 завершился: список игр тот же (только g1), но в removed появился g2
+	if err := upsertGames(ctx, pool, []Game{fixtureGame("g2", "soccer", "pre", "3", time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	second := get(etag)
+	if second.Code != http.StatusOK {
+		t.Fatalf("GET after a new removal = %d, want 200", second.Code)
+	}
+	if second.Header().Get("ETag") == etag {
+		t.Fatal("ETag unchanged after a new removal")
+	}
+	var resp struct {
+		Games []struct {
+			GameID string `json:"game_id"`
+		} `json:"games"`
+		Removed []string `json:"removed"`
+	}
+	if err := json.Unmarshal(second.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Games) != 1 || resp.Games[0].GameID != "g1" || !slices.Equal(resp.Removed, []string{"g2"}) {
+		t.Fatalf("games = %v, removed = %v, want g1 and removed [g2]", resp.Games, resp.Removed)
+	}
+}
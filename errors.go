@@ -0,0 +1,66 @@
+package main
+
+import "errors"
+
+// --- ERROR KINDS ---
+// Ошибки синка трёх видов, чтобы вызывающий мог реагировать по-разному: апстрим недоступен
+// (повторить позже), ответ не разобрался (смотреть архив и парсер), БД (отступить).
+// Исходная ошибка доступна через errors.Is/As: errors.Is(err, errCircuitOpen) по-прежнему работает.
+
+// UpstreamError — сеть, таймаут, 5xx, отказ в доступе (401/403) или открытый circuit breaker.
+type UpstreamError struct {
+	Task   string // pre / live / liveodds
+	Status int    // HTTP-статус; 0 — ответа не было
+	Err    error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Task == "" {
+		return "upstream: " + e.Err.Error()
+	}
+	return "upstream " + e.Task + ": " + e.Err.Error()
+}
+
+func (e *UpstreamError) Unwrap() error { return e.Err }
+
+// ParseError — ответ апстрима получен, но разобрать его не удалось.
+type ParseError struct {
+	Task string
+	Err  error
+}
+
+func (e *ParseError) Error() string { return "parse " + e.Task + ": " + e.Err.Error() }
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// DBError — ошибка чтения или записи в Postgres; Op — что делали ("upsert games").
+type DBError struct {
+	Op  string
+	Err error
+}
+
+func (e *DBError) Error() string { return e.Op + ": " + e.Err.Error() }
+func (e *DBError) Unwrap() error { return e.Err }
+
+// dbError оборачивает err в DBError; nil остаётся nil.
+func dbError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &DBError{Op: op, Err: err}
+}
+
+// errorKind — upstream / parse / db или "" для прочих ошибок; для /stats и ответов синк-ручек.
+func errorKind(err error) string {
+	var upstreamErr *UpstreamError
+	var parseErr *ParseError
+	var dbErr *DBError
+	switch {
+	case errors.As(err, &upstreamErr):
+		return "upstream"
+	case errors.As(err, &parseErr):
+		return "parse"
+	case errors.As(err, &dbErr):
+		return "db"
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- PRICE EVENTS ---
+
+type PriceEventView struct {
+	Bookmaker     string    `json:"bookmaker"`
+	MarketID      string    `json:"market_id"`
+	SelectionID   string    `json:"selection_id"`
+	SelectionName string    `json:"selection_name"`
+	OldPrice      float64   `json:"old_price"`
+	NewPrice      float64   `json:"new_price"`
+	Delta         float64   `json:"delta"`
+	TS            time.Time `json:"ts"`
+}
+
+// GET /api/games/:id/events?limit=50 — последние изменения цен по матчу, новые первыми.
+func gameEventsHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		limit, err := queryInt(c, "limit", 50, 1, 500)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT bookmaker, market_id, selection_id, selection_name,
+			       old_price::float8, new_price::float8, delta::float8, ts
+			FROM price_events
+			WHERE game_id = $1
+			ORDER BY ts DESC, id DESC
+			LIMIT $2`, c.Param("id"), limit)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []PriceEventView{}
+		for rows.Next() {
+			var e PriceEventView
+			if err := rows.Scan(&e.Bookmaker, &e.MarketID, &e.SelectionID, &e.SelectionName,
+				&e.OldPrice, &e.NewPrice, &e.Delta, &e.TS); err != nil {
+				serverError(c, err)
+				return
+			}
+			out = append(out, e)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		respondList(c, "events", out, len(out), limit, 0, gin.H{"game_id": c.Param("id")})
+	}
+}
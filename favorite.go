@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// --- FAVORITE ---
+
+// FavoriteView — исход основного рынка с самой короткой ценой.
+type FavoriteView struct {
+	Bookmaker          string   `json:"bookmaker"`
+	MarketID           string   `json:"market_id"`
+	MarketName         string   `json:"market_name"`
+	SelectionName      string   `json:"selection_name"`
+	Price              float64  `json:"price"`
+	ImpliedProbability float64  `json:"implied_probability"` // 1 / price
+	FairProbability    *float64 `json:"fair_probability"`    // без маржи букмекера; null, если рынок у него неполный
+}
+
+// favoriteOf выбирает фаворита среди открытых исходов с ценой; nil — таких нет.
+// Справедливая вероятность считается по рынку того же букмекера, только если у него
+// есть цены на все selection_count исходов — иначе маржу не из чего вычесть.
+func favoriteOf(odds []ListOddView) *FavoriteView {
+	var best *ListOddView
+	for i, o := range odds {
+		if o.Price == nil || *o.Price <= 1 || o.Status != "open" {
+			continue
+		}
+		if best == nil || *o.Price < *best.Price {
+			best = &odds[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	fav := &FavoriteView{
+		Bookmaker:          best.Bookmaker,
+		MarketID:           best.MarketID,
+		MarketName:         best.MarketName,
+		SelectionName:      best.SelectionName,
+		Price:              *best.Price,
+		ImpliedProbability: roundProbability(1 / *best.Price),
+	}
+
+	overround, n := 0.0, 0
+	for _, o := range odds {
+		if o.Bookmaker == best.Bookmaker && o.MarketID == best.MarketID && o.Price != nil && *o.Price > 1 && o.Status == "open" {
+			overround += 1 / *o.Price
+			n++
+		}
+	}
+	if best.SelectionCount > 0 && n == best.SelectionCount {
+		fair := roundProbability(1 / *best.Price / overround)
+		fav.FairProbability = &fair
+	}
+	return fav
+}
+
+func roundProbability(p float64) float64 {
+	return math.Round(p*1e4) / 1e4
+}
+
+// GET /api/games/:id/favorite — фаворит матча по основному рынку (PRIMARY_MARKET_<SPORT>).
+// Если основного рынка у спорта нет или открытых цен по нему нет, favorite = null.
+func gameFavoriteHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		id := c.Param("id")
+		sport, err := getGameSport(c.Request.Context(), db, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(404, gin.H{"error": "game not found"})
+			return
+		}
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+
+		markets := primaryMarkets(sport)
+		odds, err := loadPrimaryOdds(c.Request.Context(), db, []string{id}, markets)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{
+			"game_id":         id,
+			"primary_markets": markets,
+			"favorite":        favoriteOf(odds[id]),
+		})
+	}
+}
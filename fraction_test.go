@@ -0,0 +1,136 @@
+package main
+
+import "testing"
+
+// withRounding задаёт точность и режим округления цен на время теста.
+func withRounding(t *testing.T, places int, mode string) {
+	t.Helper()
+	savedPlaces, savedMode := oddsDecimalPlaces, oddsRounding
+	t.Cleanup(func() { oddsDecimalPlaces, oddsRounding = savedPlaces, savedMode })
+	oddsDecimalPlaces, oddsRounding = places, mode
+}
+
+func TestFracToDecimal(t *testing.T) {
+	withRounding(t, 3, RoundHalfUp)
+
+	tests := []struct {
+		in, dec, frac string
+		ok            bool
+	}{
+		{"1/2", "1.5", "1/2", true},
+		{"10/11", "1.909", "10/11", true},
+		{"2/3", "1.667", "2/3", true},
+		{"1/8", "1.125", "1/8", true},
+		{"0/1", "1", "0/1", true},
+		{"100/1", "101", "100/1", true},
+		{" 5/4 ", "2.25", "5/4", true},
+		{"5/0", "", "5/0", false},
+		{"evens", "", "evens", false},
+		{"2.5", "", "2.5", false},
+		{"", "", "", false},
+		{"1/2/3", "", "1/2/3", false},
+		{"a/b", "", "a/b", false},
+	}
+	for _, tt := range tests {
+		dec, frac, ok := fracToDecimal(tt.in)
+		if dec != tt.dec || frac != tt.frac || ok != tt.ok {
+			t.Errorf("fracToDecimal(%q) = %q, %q, %v; want %q, %q, %v", tt.in, dec, frac, ok, tt.dec, tt.frac, tt.ok)
+		}
+	}
+}
+
+func TestFracToDecimalPrecision(t *testing.T) {
+	tests := []struct {
+		places int
+		in     string
+		want   string
+	}{
+		{2, "10/11", "1.91"},
+		{0, "10/11", "2"},
+		{-1, "1/3", "1.3333333333333333"},
+	}
+	for _, tt := range tests {
+		withRounding(t, tt.places, RoundHalfUp)
+		if dec, _, _ := fracToDecimal(tt.in); dec != tt.want {
+			t.Errorf("places=%d fracToDecimal(%q) = %q, want %q", tt.places, tt.in, dec, tt.want)
+		}
+	}
+}
+
+func TestRoundPricePlaces(t *testing.T) {
+	tests := []struct {
+		places int
+		in     float64
+		want   float64
+	}{
+		{3, 1.9090909, 1.909},
+		{3, 1.6666666, 1.667},
+		{3, 2.0125, 2.013}, // .xxx5 во float64 — 2.01249999…, округляется по десятичной записи
+		{3, 1.5, 1.5},
+		{2, 1.9090909, 1.91},
+		{2, 1.6666666, 1.67},
+		{2, 2.125, 2.13},
+		{2, 1.004, 1},
+		{-1, 1.9090909, 1.9090909},
+	}
+	for _, tt := range tests {
+		withRounding(t, tt.places, RoundHalfUp)
+		if got := roundPrice(tt.in); got != tt.want {
+			t.Errorf("places=%d roundPrice(%v) = %v, want %v", tt.places, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOddsDecimalPlacesConfig(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/jonathan")
+	for _, tt := range []struct {
+		env   string
+		want  int
+		valid bool
+	}{
+		{"", 3, true},
+		{"2", 2, true},
+		{"-1", -1, true},
+		{"11", 11, false},
+		{"-2", -2, false},
+	} {
+		t.Setenv("ODDS_DECIMAL_PLACES", tt.env)
+		cfg, err := loadConfig()
+		if cfg.OddsDecimalPlaces != tt.want || (err == nil) != tt.valid {
+			t.Errorf("ODDS_DECIMAL_PLACES=%q: places=%d err=%v, want %d valid=%v", tt.env, cfg.OddsDecimalPlaces, err, tt.want, tt.valid)
+		}
+	}
+}
+
+func TestRoundPriceModes(t *testing.T) {
+	tests := []struct {
+		in              float64
+		halfUp, bankers float64
+	}{
+		{2.0125, 2.013, 2.012},
+		{2.0135, 2.014, 2.014},
+		{1.0005, 1.001, 1},
+		{3.4445, 3.445, 3.444},
+		{1.9091, 1.909, 1.909}, // не на границе — режимы совпадают
+	}
+	for _, tt := range tests {
+		withRounding(t, 3, RoundHalfUp)
+		if got := roundPrice(tt.in); got != tt.halfUp {
+			t.Errorf("half_up roundPrice(%v) = %v, want %v", tt.in, got, tt.halfUp)
+		}
+		withRounding(t, 3, RoundBankers)
+		if got := roundPrice(tt.in); got != tt.bankers {
+			t.Errorf("bankers roundPrice(%v) = %v, want %v", tt.in, got, tt.bankers)
+		}
+	}
+
+	// та же граница через конвертацию дроби: 1 + 81/80 = 2.0125
+	withRounding(t, 3, RoundHalfUp)
+	if dec, _, _ := fracToDecimal("81/80"); dec != "2.013" {
+		t.Errorf("half_up fracToDecimal(81/80) = %q, want 2.013", dec)
+	}
+	withRounding(t, 3, RoundBankers)
+	if dec, _, _ := fracToDecimal("81/80"); dec != "2.012" {
+		t.Errorf("bankers fracToDecimal(81/80) = %q, want 2.012", dec)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergeGamesLiveWinsOverPre(t *testing.T) {
+	pre := Game{GameID: "1", Source: "pre", TimeStatus: "0", Scores: ""}
+	live := Game{GameID: "1", Source: "live", TimeStatus: "1", Scores: "1-0"}
+	other := Game{GameID: "2", Source: "pre", TimeStatus: "0"}
+
+	tests := []struct {
+		name string
+		in   []Game
+	}{
+		{"pre then live", []Game{pre, other, live}},
+		{"live then stale pre", []Game{live, other, pre}},
+	}
+	for _, tt := range tests {
+		got := mergeGames(tt.in)
+		if len(got) != 2 {
+			t.Fatalf("%s: %d games, want 2", tt.name, len(got))
+		}
+		if got[0] != live || got[1] != other {
+			t.Errorf("%s: got %+v, want live record first and game 2 untouched", tt.name, got)
+		}
+	}
+}
+
+func TestNormalizeTimeStatus(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, ""},
+		{"1", "1"},
+		{" 1 ", "1"},
+		{"1.0", "1"},
+		{"03", "3"},
+		{1.0, "1"},
+		{float64(3), "3"},
+		{json.Number("1"), "1"},
+		{json.Number("1.0"), "1"},
+		{json.Number("10"), "10"},
+		{1.5, "1.5"},
+		{"live", "live"},
+		{" ended ", "ended"},
+		{"", ""},
+		{true, "true"},
+	}
+	for _, tt := range tests {
+		if got := normalizeTimeStatus(tt.in); got != tt.want {
+			t.Errorf("normalizeTimeStatus(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseLiveGamesTimeStatusVariants(t *testing.T) {
+	body := `{"games":[
+		{"game_id":"1","time_status":1},
+		{"game_id":"2","time_status":"1"},
+		{"game_id":"3","time_status":1.0},
+		{"game_id":"4","time_status":" 1 "},
+		{"game_id":"5","time_status":"3"}]}`
+	games, err := parseLiveGames([]byte(body), "soccer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"1": "1", "2": "1", "3": "1", "4": "1", "5": "3"}
+	if len(games) != len(want) {
+		t.Fatalf("%d games, want %d", len(games), len(want))
+	}
+	for _, g := range games {
+		if g.TimeStatus != want[g.GameID] {
+			t.Errorf("game %s: time_status %q, want %q", g.GameID, g.TimeStatus, want[g.GameID])
+		}
+	}
+}
+
+func TestStrField(t *testing.T) {
+	m := map[string]any{
+		"str":    "Arsenal",
+		"null":   nil,
+		"num":    json.Number("151234567"),
+		"float":  1.5,
+		"bigflt": 12345678.0,
+		"bool":   true,
+	}
+	for key, want := range map[string]string{
+		"str": "Arsenal", "null": "", "missing": "", "num": "151234567",
+		"float": "1.5", "bigflt": "12345678", "bool": "true",
+	} {
+		if got := strField(m, key); got != want {
+			t.Errorf("strField(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestParseLiveGamesMissingFields(t *testing.T) {
+	body := `{"games":[{"game_id":"1","home":null,"time_status":"1"},{"game_id":"2"},{"home":"No ID"}]}`
+	games, err := parseLiveGames([]byte(body), "soccer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("%d games, want 2 (game without game_id skipped)", len(games))
+	}
+	for _, g := range games {
+		for field, v := range map[string]string{"league": g.League, "home": g.Home, "away": g.Away, "scores": g.Scores} {
+			if v != "" {
+				t.Errorf("game %s: %s = %q, want empty", g.GameID, field, v)
+			}
+		}
+		if g.StartsAt != nil {
+			t.Errorf("game %s: starts_at = %v, want nil", g.GameID, g.StartsAt)
+		}
+	}
+	if games[1].TimeStatus != "" {
+		t.Errorf("game 2: time_status = %q, want empty", games[1].TimeStatus)
+	}
+}
+
+func TestLargeNumericIDsKeepAllDigits(t *testing.T) {
+	body := `{"games":[{"game_id":12345678,"time_status":1},{"game_id":9007199254740993,"time_status":1}]}`
+	games, err := parseLiveGames([]byte(body), "soccer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 || games[0].GameID != "12345678" || games[1].GameID != "9007199254740993" {
+		t.Fatalf("game ids = %+v, want 12345678 and 9007199254740993 verbatim", games)
+	}
+
+	pre, err := parsePreGames([]byte(`{"games_pre":[{"game_id":151234567890,"time":1718454600}]}`), "soccer")
+	if err != nil || len(pre) != 1 || pre[0].GameID != "151234567890" || pre[0].StartsAt == nil {
+		t.Fatalf("pre games = %+v, %v; want game 151234567890 with starts_at", pre, err)
+	}
+
+	odds, _ := parseOddsJSON(t, `{"success":1,"results":[[
+		{"type":"MG","ID":1234567890123,"NA":"Fulltime Result"},
+		{"type":"PA","ID":9007199254740993,"NA":"Home","OD":"1/2"}]]}`, "soccer")
+	if len(odds) != 1 || odds[0].MarketID != "1234567890123" || odds[0].SelectionID != "9007199254740993" {
+		t.Fatalf("odds = %+v, want market 1234567890123 and selection 9007199254740993", odds)
+	}
+}
+
+func TestParsePreGamesTypeVariedFields(t *testing.T) {
+	body := `{"games_pre":[
+		{"game_id":"1","league":"EPL","home":"Arsenal","away":"Chelsea","time":"1718454600","time_status":"0"},
+		{"game_id":2,"league":{"id":94,"name":"EPL"},"home":"Spurs","away":"Everton","time":1718454600,"time_status":0},
+		{"game_id":"3","home":["broken"],"time":"soon","new_field":{"x":1}},
+		"not an object",
+		{"game_id":null}]}`
+	games, err := parsePreGames([]byte(body), "soccer")
+	if err != nil {
+		t.Fatalf("parsePreGames: %v", err)
+	}
+	if len(games) != 3 {
+		t.Fatalf("%d games, want 3", len(games))
+	}
+	if g := games[0]; g.GameID != "1" || g.Home != "Arsenal" || g.StartsAt == nil || g.TimeStatus != "0" {
+		t.Errorf("string fields: %+v", g)
+	}
+	if g := games[1]; g.GameID != "2" || g.Home != "Spurs" || g.StartsAt == nil || g.TimeStatus != "0" {
+		t.Errorf("numeric fields: %+v", g)
+	}
+	if g := games[2]; g.GameID != "3" || g.StartsAt != nil {
+		t.Errorf("unparseable time: %+v, want starts_at nil", g)
+	}
+	if !games[0].StartsAt.Equal(*games[1].StartsAt) {
+		t.Errorf("time as string %v and as number %v differ", games[0].StartsAt, games[1].StartsAt)
+	}
+}
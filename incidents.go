@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- INCIDENTS ---
+
+// Incident — событие матча (гол, карточка, угловой) из элементов "EV" ответа liveodds.
+type Incident struct {
+	GameID      string
+	IncidentID  string
+	Type        string
+	Minute      *int
+	Description string
+}
+
+// Канонические типы событий
+const (
+	IncidentGoal       = "goal"
+	IncidentYellowCard = "yellow_card"
+	IncidentRedCard    = "red_card"
+	IncidentCorner     = "corner"
+	IncidentOther      = "other"
+)
+
+// parseIncidents достаёт из ответа liveodds элементы типа EV.
+// Элементы без ID пропускаются: по ID событие дедуплицируется между синками и букмекерами.
+func parseIncidents(apiResp APIResponse, gameID string) []Incident {
+	var out []Incident
+	for _, group := range apiResp.Results {
+		for _, item := range group {
+			if strField(item, "type") != "EV" {
+				continue
+			}
+			id := strField(item, "ID")
+			if id == "" {
+				continue
+			}
+			desc := strings.TrimSpace(strField(item, "NA"))
+			out = append(out, Incident{
+				GameID:      gameID,
+				IncidentID:  id,
+				Type:        incidentType(desc),
+				Minute:      incidentMinute(item["TM"]),
+				Description: desc,
+			})
+		}
+	}
+	return out
+}
+
+func incidentType(desc string) string {
+	d := strings.ToLower(desc)
+	switch {
+	case strings.Contains(d, "red card"):
+		return IncidentRedCard
+	case strings.Contains(d, "yellow card"), strings.Contains(d, "booking"):
+		return IncidentYellowCard
+	case strings.Contains(d, "goal"):
+		return IncidentGoal
+	case strings.Contains(d, "corner"):
+		return IncidentCorner
+	default:
+		return IncidentOther
+	}
+}
+
+// incidentMinute — минута события; "45+2" считается как 45.
+func incidentMinute(v any) *int {
+	s := strings.TrimSpace(fmt.Sprintf("%v", v))
+	if i := strings.IndexAny(s, "+'"); i >= 0 {
+		s = s[:i]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return nil
+	}
+	return &n
+}
+
+// insertIncidents пишет события матча; уже сохранённые (тот же game_id + incident_id) не трогает.
+func insertIncidents(ctx context.Context, pool *pgxpool.Pool, incidents []Incident) error {
+	if len(incidents) == 0 {
+		return nil
+	}
+	defer logSlow(ctx, time.Now(), "insert incidents", fmt.Sprintf("game_id=%s count=%d", incidents[0].GameID, len(incidents)))
+
+	err := withTx(ctx, pool, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, in := range incidents {
+			batch.Queue(`
+				INSERT INTO incidents (game_id, incident_id, type, minute, description, fetched_at)
+				VALUES ($1,$2,$3,$4,$5,now())
+				ON CONFLICT (game_id, incident_id) DO NOTHING
+			`, in.GameID, in.IncidentID, in.Type, in.Minute, in.Description)
+		}
+		return execBatch(ctx, tx, batch)
+	})
+	return dbError("insert incidents", err)
+}
+
+type IncidentView struct {
+	Type        string    `json:"type"`
+	Minute      *int      `json:"minute"`
+	Description string    `json:"description"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// GET /api/games/:id/incidents — события матча по ходу игры (по минуте, затем по времени получения).
+func gameIncidentsHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT type, minute, description, fetched_at
+			FROM incidents
+			WHERE game_id = $1
+			ORDER BY minute NULLS LAST, fetched_at, id`, c.Param("id"))
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []IncidentView{}
+		for rows.Next() {
+			var in IncidentView
+			if err := rows.Scan(&in.Type, &in.Minute, &in.Description, &in.FetchedAt); err != nil {
+				serverError(c, err)
+				return
+			}
+			out = append(out, in)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		respondList(c, "incidents", out, len(out), 0, 0, gin.H{"game_id": c.Param("id")})
+	}
+}
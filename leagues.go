@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// --- LEAGUE FILTER ---
+// LEAGUE_ALLOWLIST / LEAGUE_BLOCKLIST — шаблоны лиг через запятую, без учёта регистра,
+// с wildcard'ами * и ?: "England Premier League,UEFA *,*U21*".
+// Если allowlist задан, остаются только подходящие лиги; blocklist применяется после него.
+
+func wildcardRegexp(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(strings.ToLower(strings.TrimSpace(pattern)))
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
+func leaguePatterns(key string) []*regexp.Regexp {
+	var out []*regexp.Regexp
+	for _, p := range getEnvList(key, nil) {
+		out = append(out, wildcardRegexp(p))
+	}
+	return out
+}
+
+func matchesAny(patterns []*regexp.Regexp, league string) bool {
+	league = strings.ToLower(strings.TrimSpace(league))
+	for _, re := range patterns {
+		if re.MatchString(league) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLeagues отбрасывает матчи неинтересных лиг до записи в БД.
+func filterLeagues(ctx context.Context, games []Game, feed string) []Game {
+	allow := leaguePatterns("LEAGUE_ALLOWLIST")
+	block := leaguePatterns("LEAGUE_BLOCKLIST")
+	if len(allow) == 0 && len(block) == 0 {
+		return games
+	}
+
+	out := games[:0]
+	for _, g := range games {
+		if len(allow) > 0 && !matchesAny(allow, g.League) {
+			continue
+		}
+		if matchesAny(block, g.League) {
+			continue
+		}
+		out = append(out, g)
+	}
+	if dropped := len(games) - len(out); dropped > 0 {
+		logf(ctx, "🚫 %s: filtered out %d of %d games by league", feed, dropped, len(games))
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- LIVE ---
+
+// LiveGameView — live-матч с коэффициентами основного рынка (PRIMARY_MARKET_<SPORT>).
+type LiveGameView struct {
+	GameView
+	Odds      []ListOddView `json:"odds"`
+	OddsError bool          `json:"odds_error,omitempty"`
+}
+
+// GET /api/live — все live-матчи (time_status=1) с коэффициентами основного рынка за два запроса:
+// матчи, затем исходы всех матчей через game_id = ANY. Не больше LIVE_MAX_GAMES (300) матчей,
+// при обрезке в ответе truncated=true. Порядок: лига, время начала, game_id.
+func liveGamesHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		ctx := c.Request.Context()
+		maxGames := getEnvInt("LIVE_MAX_GAMES", 300)
+		formats, err := queryOddsFormats(c)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		rows, err := db.Query(ctx, `
+			SELECT game_id, sport, league, home_team, away_team, scores, time_status, starts_at, starts_at_estimated, scores_detail
+			FROM games
+			WHERE time_status = '1' AND missed_syncs < $1
+			ORDER BY league, starts_at NULLS LAST, game_id
+			LIMIT $2`, missingSyncsLimit(), maxGames+1)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		out := []LiveGameView{}
+		for rows.Next() {
+			var g LiveGameView
+			if err := rows.Scan(&g.GameID, &g.Sport, &g.League, &g.Home, &g.Away, &g.Scores, &g.Time, &g.StartsAt, &g.StartsAtEstimated, &g.ScoresDetail); err != nil {
+				rows.Close()
+				serverError(c, err)
+				return
+			}
+			out = append(out, g)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+		truncated := len(out) > maxGames
+		if truncated {
+			out = out[:maxGames]
+		}
+
+		ids := make([]string, len(out))
+		var markets []string
+		for i, g := range out {
+			ids[i] = g.GameID
+			for _, m := range primaryMarkets(g.Sport) {
+				if !slices.Contains(markets, m) {
+					markets = append(markets, m)
+				}
+			}
+		}
+		odds, err := loadPrimaryOdds(ctx, db, ids, markets)
+		if err != nil {
+			logf(ctx, "❌ Live odds query error: %v", err)
+		}
+		for i := range out {
+			out[i].Odds = []ListOddView{}
+			if err != nil {
+				out[i].OddsError = true
+				continue
+			}
+			// рынки собраны по всем видам спорта — оставляем только основной для спорта матча
+			out[i].Odds = primaryOdds(out[i].Sport, odds[out[i].GameID])
+			formats.applyList(out[i].Odds)
+		}
+
+		respondList(c, "games", out, len(out), maxGames, 0, gin.H{"truncated": truncated})
+	}
+}
+
+// loadPrimaryOdds — облегчённые исходы нескольких матчей одним запросом, по game_id.
+// markets — market_id или названия рынков в нижнем регистре.
+func loadPrimaryOdds(ctx context.Context, db *pgxpool.Pool, gameIDs, markets []string) (map[string][]ListOddView, error) {
+	out := map[string][]ListOddView{}
+	if len(gameIDs) == 0 || len(markets) == 0 {
+		return out, nil
+	}
+	rows, err := db.Query(ctx, `
+		SELECT game_id, bookmaker, market_id, market_name, market_shape, selection_count, selection_name, price_dec::text, price_dec::float8, is_suspended, status,
+		       `+isNewExpr("")+`
+		FROM liveodds
+		WHERE game_id = ANY($1) AND (lower(market_id) = ANY($2) OR lower(market_name) = ANY($2))
+		ORDER BY game_id, market_id, selection_id, bookmaker`,
+		gameIDs, markets,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var gameID string
+		var o ListOddView
+		if err := rows.Scan(&gameID, &o.Bookmaker, &o.MarketID, &o.MarketName, &o.MarketShape, &o.SelectionCount,
+			&o.SelectionName, &o.PriceDec, &o.Price, &o.IsSuspended, &o.Status, &o.IsNew); err != nil {
+			return nil, err
+		}
+		out[gameID] = append(out[gameID], o)
+	}
+	return out, rows.Err()
+}
@@ -1,22 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
+	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"golang.org/x/sync/errgroup"
 )
 
 // --- MODELS ---
@@ -31,6 +42,8 @@ type Game struct {
 	Scores     string
 	TimeStatus string
 	StartsAt   *time.Time
+
+	ScoresDetail *ScoreBoard // только теннис
 }
 
 type LiveOdd struct {
@@ -42,10 +55,15 @@ type LiveOdd struct {
 	SelectionID   string
 	SelectionName string
 	Line          string
-	PriceDec      string
+	LineType      string // handicap / total / other / none — что значит Line, см. lineType
+	PriceDec      string // "" — не удалось пересчитать из дроби, в БД пишется NULL
 	PriceFrac     string
-	FetchedAt     time.Time
 	Raw           string
+
+	SelectionCount int
+	MarketShape    string
+	MarketKey      string
+	IsSuspended    bool
 }
 
 type APIResponse struct {
@@ -65,8 +83,50 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-func connectDB() (*pgxpool.Pool, error) {
-	dbURL := getEnv("DATABASE_URL", "")
+func getEnvInt(key string, fallback int) int {
+	if val := os.Getenv(key); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			return n
+		}
+		log.Printf("⚠️ Invalid %s=%q, using %d", key, val, fallback)
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+		log.Printf("⚠️ Invalid %s=%q, using %g", key, val, fallback)
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+		log.Printf("⚠️ Invalid %s=%q, using %t", key, val, fallback)
+	}
+	return fallback
+}
+
+func getEnvList(key string, fallback []string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return fallback
+	}
+	return out
+}
+
+func connectDB(dbURL string) (*pgxpool.Pool, error) {
 	return pgxpool.New(context.Background(), dbURL)
 }
 
@@ -74,203 +134,397 @@ func connectDB() (*pgxpool.Pool, error) {
 func main() {
 	loadEnv()
 
-	db, err := connectDB()
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("❌ Invalid config: %v", err)
+	}
+
+	// отменяется по SIGINT/SIGTERM — см. shutdown.go
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbHealthInterval = cfg.DBHealthInterval // до первого healthLoop
+
+	pool, err := connectDB(cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("❌ DB connection failed: %v", err)
 	}
-	defer db.Close()
+	dbh := newDBHandle("DB", cfg.DatabaseURL, pool)
+	defer dbh.Close()
 
-	r := gin.Default()
+	// /api читает из реплики (READONLY_DATABASE_URL), чтобы пачка синков не забирала
+	// соединения у запросов фронтенда. Без реплики — тот же пул.
+	readDBH := dbh
+	if cfg.ReadOnlyDatabaseURL != "" {
+		readPool, err := connectDB(cfg.ReadOnlyDatabaseURL)
+		if err != nil {
+			log.Fatalf("❌ Read DB connection failed: %v", err)
+		}
+		readDBH = newDBHandle("Read DB", cfg.ReadOnlyDatabaseURL, readPool)
+		defer readDBH.Close()
+		go readDBH.healthLoop(ctx)
+		log.Printf("📖 API reads go to READONLY_DATABASE_URL")
+	}
+
+	if err := migrate(pool); err != nil {
+		log.Fatalf("❌ Migration failed: %v", err)
+	}
+	go dbh.healthLoop(ctx)
+
+	sports = cfg.Sports
+	oddsDecimalPlaces = cfg.OddsDecimalPlaces
+	oddsRounding = cfg.OddsRounding
+	batchChunkSize = cfg.BatchChunkSize
+	upstreamBreaker = newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+	maintenanceWindows, maintenanceLoc = cfg.MaintenanceWindows, cfg.MaintenanceTZ
+	priceMin, priceMax = cfg.PriceMin, cfg.PriceMax
+	priceEpsilon, oddsTouchInterval = cfg.PriceEpsilon, cfg.OddsTouchInterval
+	activeTimeStatuses = cfg.ActiveTimeStatuses
+	storeRaw = cfg.StoreRaw
+	txRetryAttempts, txRetryBackoff = cfg.TxRetryAttempts, cfg.TxRetryBackoff
+	syncConcurrency = cfg.SyncConcurrency
+	newSelectionWindow = cfg.NewSelectionWindow
+
+	startScheduler(ctx, dbh)
+	go compactLoop(ctx, dbh)
+	startSelfTest(ctx, dbh)
+
+	gin.SetMode(ginMode())
+	r := gin.New()
+	r.Use(gin.Recovery(), requestID(), requestLogger())
+	r.Use(
+		bodyLimit(int64(getEnvInt("MAX_BODY_BYTES", 1<<20))),
+		requestTimeout(time.Duration(getEnvInt("REQUEST_TIMEOUT_SEC", 30))*time.Second),
+	)
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://127.0.0.1:5173"},
 		AllowMethods:     []string{"GET", "POST"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "X-Request-ID", "X-Admin-Token", "Authorization"}, // токены — см. isAdmin
+		ExposeHeaders:    []string{"X-Request-ID"},
 		AllowCredentials: true,
 	}))
-	// 1. Загрузка матчей (pre + live)
-	r.GET("/sync-games", func(c *gin.Context) {
-		all, err := fetchAllGames()
-		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		if err := upsertGames(db, all); err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(200, gin.H{"status": "✅ Games synced", "count": len(all)})
-	})
+	// 1. Загрузка матчей (pre + live); ?sport=&source= сужают набор фидов (см. syncGamesHandler)
+	// На паузе (pause.go) и во время окон обслуживания апстрима (maintenance.go) синки отвечают 503
+	r.GET("/sync-games", pauseGuard(), maintenanceGuard(), syncGamesHandler(dbh))
+	r.POST("/sync-games", pauseGuard(), maintenanceGuard(), syncGamesHandler(dbh))
 
 	// 2. Загрузка коэффициентов для live матчей
-	r.GET("/update-liveodds", func(c *gin.Context) {
-		gameIDs, err := fetchLiveGameIDs(db)
+	r.GET("/update-liveodds", pauseGuard(), maintenanceGuard(), func(c *gin.Context) {
+		inserted, _, err := updateLiveOdds(syncContext(c), dbh.Pool(), "")
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			serverError(c, err)
 			return
 		}
-
-		inserted := 0
-		for _, id := range gameIDs {
-			sport, _ := getGameSport(db, id)
-			odds, err := fetchLiveOdds(id, sport)
-			if err != nil {
-				log.Printf("❌ Fetch odds error for %s: %v", id, err)
-				continue
-			}
-			if err := insertLiveOdds(db, odds); err != nil {
-				log.Printf("❌ Insert odds error for %s: %v", id, err)
-				continue
-			}
-			inserted += len(odds)
-		}
 		c.JSON(200, gin.H{"status": "✅ Odds updated", "inserted": inserted})
 	})
-	r.GET("/api/games", func(c *gin.Context) {
-		rows, err := db.Query(context.Background(), `
-		SELECT game_id, league, home_team, away_team, time_status, starts_at
-		FROM games
-		WHERE time_status IN ('0','1')
-		ORDER BY starts_at NULLS LAST
-		LIMIT 100
-	`)
-		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
+
+	// 3. Всё сразу, в правильном порядке: матчи, затем коэффициенты
+	r.POST("/sync", pauseGuard(), maintenanceGuard(), syncHandler(dbh))
+
+	r.GET("/stats", statsHandler())
+	r.GET("/readyz", readyzHandler(dbh, readDBH))
+	r.GET("/healthz", healthzHandler())
+	r.GET("/version", versionHandler())
+
+	admin := r.Group("/admin", adminOnly())
+	admin.POST("/cache/flush", cacheFlushHandler())
+	admin.POST("/pause", pauseHandler(true))
+	admin.POST("/resume", pauseHandler(false))
+	admin.GET("/archive", archiveListHandler())
+	admin.GET("/replay", replayHandler())
+	admin.POST("/reparse/:game_id", reparseHandler(dbh))
+
+	// Чтение для фронтенда сжимаем gzip (если клиент шлёт Accept-Encoding: gzip).
+	// WebSocket (Connection: Upgrade) и SSE (Accept: text/event-stream) middleware пропускает сам.
+	api := r.Group("/api")
+	api.Use(gzip.Gzip(gzip.DefaultCompression))
+
+	api.GET("/games", listGamesHandler(readDBH))
+	api.GET("/games/upcoming", upcomingGamesHandler(readDBH))
+	api.GET("/games/suspended", suspendedGamesHandler(readDBH))
+	api.GET("/live", liveGamesHandler(readDBH))
+	api.GET("/games/:id", gameDetailHandler(readDBH))
+	api.GET("/games/:id/movement", gameMovementHandler(readDBH))
+	api.GET("/games/:id/smoothed", gameSmoothedHandler(readDBH))
+	api.GET("/games/:id/snapshot-diff", gameSnapshotDiffHandler(readDBH))
+	api.GET("/games/:id/related", relatedGamesHandler(readDBH))
+	api.GET("/games/:id/compare", gameCompareHandler(readDBH))
+	api.GET("/games/:id/favorite", gameFavoriteHandler(readDBH))
+	api.GET("/games/:id/events", gameEventsHandler(readDBH))
+	api.GET("/games/:id/incidents", gameIncidentsHandler(readDBH))
+	api.GET("/markets", listMarketsHandler(readDBH))
+	api.GET("/odds", oddsQueryHandler(readDBH))
+	api.GET("/sync-runs", syncRunsHandler(readDBH))
+	api.GET("/schema", apiSchemaHandler())
+
+	addr := net.JoinHostPort(cfg.BindAddr, cfg.Port)
+	log.Printf("🚀 Listening on %s", addr)
+	serve(ctx, &http.Server{Addr: addr, Handler: r})
+}
+
+// --- UPSTREAM ---
+
+const upstreamBase = "https://bookiesapi.com/api/get.php"
+
+// upstreamURL собирает URL запроса к bookiesapi. Логин и токен попадают в query,
+// только если не настроена передача в заголовках (UPSTREAM_LOGIN_HEADER / UPSTREAM_TOKEN_HEADER) —
+// иначе они оседают в логах прокси и в текстах ошибок net/http.
+func upstreamURL(params url.Values) string {
+	if getEnv("UPSTREAM_LOGIN_HEADER", "") == "" {
+		params.Set("login", getEnv("API_LOGIN", ""))
+	}
+	if getEnv("UPSTREAM_TOKEN_HEADER", "") == "" {
+		params.Set("token", getEnv("API_TOKEN", ""))
+	}
+	return upstreamBase + "?" + params.Encode()
+}
+
+// redactURL заменяет login и token в query на REDACTED — для текстов ошибок и логов.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<unparseable url>"
+	}
+	q := u.Query()
+	for _, key := range []string{"login", "token"} {
+		if q.Has(key) {
+			q.Set(key, "REDACTED")
 		}
-		defer rows.Close()
-
-		type G struct {
-			GameID   string     `json:"game_id"`
-			League   string     `json:"league"`
-			Home     string     `json:"home_team"`
-			Away     string     `json:"away_team"`
-			Time     string     `json:"time_status"`
-			StartsAt *time.Time `json:"starts_at"`
-			Odds     any        `json:"odds"`
-		}
-
-		var out []G
-
-		for rows.Next() {
-			var g G
-			if err := rows.Scan(&g.GameID, &g.League, &g.Home, &g.Away, &g.Time, &g.StartsAt); err == nil {
-
-				// Загружаем коэффициенты
-				oddsRows, err := db.Query(context.Background(),
-					`SELECT selection_name, price_dec FROM odds WHERE game_id = $1`,
-					g.GameID,
-				)
-				if err == nil {
-					var odds []map[string]string
-					for oddsRows.Next() {
-						var name, price string
-						if err := oddsRows.Scan(&name, &price); err == nil {
-							odds = append(odds, map[string]string{
-								"selection_name": name,
-								"price_dec":      price,
-							})
-						}
-					}
-					oddsRows.Close()
-					g.Odds = odds
-				} else {
-					g.Odds = []map[string]string{}
-				}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
 
-				out = append(out, g)
-			}
+// redactURLError — ошибки net/http (*url.Error) содержат полный URL запроса, а с ним и API_TOKEN.
+// Возвращает копию с вычищенным URL; Timeout() и цепочка Unwrap сохраняются.
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	return &url.Error{Op: urlErr.Op, URL: redactURL(urlErr.URL), Err: urlErr.Err}
+}
+
+// redactSecrets вырезает API_LOGIN/API_TOKEN из текста, который уходит клиенту (warnings /sync):
+// страховка на случай, если секрет попал в ошибку не через URL.
+func redactSecrets(s string) string {
+	for _, key := range []string{"API_TOKEN", "API_LOGIN"} {
+		secret := getEnv(key, "")
+		if len(secret) < 4 { // замена пары символов испортила бы текст, а секретом она не является
+			continue
 		}
+		s = strings.ReplaceAll(s, secret, "REDACTED")
+		s = strings.ReplaceAll(s, url.QueryEscape(secret), "REDACTED")
+	}
+	return s
+}
 
-		c.JSON(200, gin.H{"games": out})
-	})
+// upstreamHeaders — заголовки каждого запроса к апстриму:
+//
+//	UPSTREAM_USER_AGENT   — User-Agent (по умолчанию jonathan/<commit>)
+//	UPSTREAM_LOGIN_HEADER — имя заголовка для API_LOGIN (вместо query)
+//	UPSTREAM_TOKEN_HEADER — имя заголовка для API_TOKEN (вместо query)
+//	UPSTREAM_HEADERS      — дополнительные "Name: value" через запятую
+func upstreamHeaders() http.Header {
+	h := http.Header{}
+	h.Set("User-Agent", getEnv("UPSTREAM_USER_AGENT", "jonathan/"+gitCommit))
+	if name := getEnv("UPSTREAM_LOGIN_HEADER", ""); name != "" {
+		h.Set(name, getEnv("API_LOGIN", ""))
+	}
+	if name := getEnv("UPSTREAM_TOKEN_HEADER", ""); name != "" {
+		h.Set(name, getEnv("API_TOKEN", ""))
+	}
+	for _, kv := range getEnvList("UPSTREAM_HEADERS", nil) {
+		name, value, ok := strings.Cut(kv, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			log.Printf("⚠️ Invalid UPSTREAM_HEADERS entry %q, expected Name: value", kv)
+			continue
+		}
+		h.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return h
+}
 
-	r.Run(":" + getEnv("PORT", "9090"))
+// upstreamGet — все запросы к bookiesapi идут через circuit breaker.
+// Сетевая ошибка или 5xx считаются отказом апстрима. Все ошибки — *UpstreamError.
+func upstreamGet(ctx context.Context, rawURL string) (*http.Response, error) {
+	if err := upstreamBreaker.allow(); err != nil {
+		return nil, &UpstreamError{Err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		upstreamBreaker.release()
+		return nil, &UpstreamError{Err: err}
+	}
+	req.Header = upstreamHeaders()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		upstreamBreaker.failure()
+		return nil, &UpstreamError{Err: redactURLError(err)}
+	}
+	if resp.StatusCode >= 500 {
+		resp.Body.Close()
+		upstreamBreaker.failure()
+		return nil, &UpstreamError{Status: resp.StatusCode, Err: fmt.Errorf("returned %s", resp.Status)}
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		// апстрим жив, проблема в токене — состояние breaker не меняем (только отпускаем пробу),
+		// но и тело ошибки не разбираем как данные
+		resp.Body.Close()
+		upstreamBreaker.release()
+		return nil, &UpstreamError{Status: resp.StatusCode, Err: fmt.Errorf("returned %s, check API_LOGIN/API_TOKEN", resp.Status)}
+	}
+	upstreamBreaker.success()
+	return resp, nil
 }
 
 // --- GAME FETCHING ---
 
-func fetchAllGames() ([]Game, error) {
-	var all []Game
-	if g, err := fetchPreGames("soccer"); err == nil {
-		all = append(all, g...)
+// Виды спорта для синхронизации (SPORTS, через запятую)
+var sports = []string{"soccer", "tennis"}
+
+// Источники матчей апстрима, в порядке загрузки
+var gameSources = []string{"pre", "live"}
+
+// Сколько фидов (source/sport) fetchAllGames загружает одновременно (SYNC_CONCURRENCY)
+var syncConcurrency = 4
+
+// fetchAllGames загружает матчи по всем сочетаниям sportList × sources, до syncConcurrency параллельно.
+// Порядок результата прежний — сначала pre, потом live, виды спорта по порядку, — чтобы
+// mergeGames разрешал дубли так же, как при последовательной загрузке.
+// Ошибка отдельного фида логируется и не мешает остальным; если упали все — возвращается
+// их errors.Join (errorKind и errors.As видят исходные UpstreamError/ParseError).
+func fetchAllGames(ctx context.Context, sportList, sources []string) ([]Game, error) {
+	type feed struct {
+		source, sport string
+		fetch         func(context.Context, string) ([]Game, error)
+		games         []Game
+		err           error
 	}
-	if g, err := fetchPreGames("tennis"); err == nil {
-		all = append(all, g...)
+	var feeds []*feed
+	for _, source := range gameSources {
+		if !slices.Contains(sources, source) {
+			continue
+		}
+		fetch := fetchPreGames
+		if source == "live" {
+			fetch = fetchLiveGames
+		}
+		for _, sport := range sportList {
+			feeds = append(feeds, &feed{source: source, sport: sport, fetch: fetch})
+		}
 	}
-	if g, err := fetchLiveGames("soccer"); err == nil {
-		all = append(all, g...)
+
+	var g errgroup.Group
+	g.SetLimit(max(syncConcurrency, 1))
+	for _, f := range feeds {
+		g.Go(func() error {
+			games, err := f.fetch(ctx, f.sport)
+			if err != nil {
+				logf(ctx, "❌ Fetch %s/%s games error: %v", f.source, f.sport, err)
+				f.err = err
+				return nil
+			}
+			feedCounts.observe(ctx, f.source+"/"+f.sport, len(games))
+			f.games = games
+			return nil
+		})
 	}
-	if g, err := fetchLiveGames("tennis"); err == nil {
-		all = append(all, g...)
+	g.Wait()
+
+	// Упавший фид не попадает в all, и markMissingGames его матчи не трогает — остальные
+	// фиды сохраняем. Если не ответил ни один, прогон — ошибка с исходными UpstreamError/ParseError.
+	var all []Game
+	var errs []error
+	for _, f := range feeds {
+		all = append(all, f.games...)
+		if f.err != nil {
+			errs = append(errs, f.err)
+		}
+	}
+	if len(feeds) > 0 && len(errs) == len(feeds) {
+		return nil, errors.Join(errs...)
 	}
 	return all, nil
 }
 
-func fetchPreGames(sport string) ([]Game, error) {
-	login := getEnv("API_LOGIN", "")
-	token := getEnv("API_TOKEN", "")
-	url := fmt.Sprintf("https://bookiesapi.com/api/get.php?login=%s&token=%s&task=pre&bookmaker=bet365&sport=%s",
-		login, token, sport)
+func fetchPreGames(ctx context.Context, sport string) ([]Game, error) {
+	defer logSlow(ctx, time.Now(), "fetch pre", "sport="+sport)
+	reqURL := upstreamURL(url.Values{"task": {"pre"}, "bookmaker": {"bet365"}, "sport": {sport}})
 
-	var resp struct {
-		Games []struct {
-			GameID     string `json:"game_id"`
-			Time       string `json:"time"`
-			TimeStatus string `json:"time_status"`
-			League     string `json:"league"`
-			Home       string `json:"home"`
-			Away       string `json:"away"`
-			Scores     string `json:"scores"`
-		} `json:"games_pre"`
+	body, err := upstreamFetch(ctx, reqURL, archiveMeta{Task: "pre", Sport: sport})
+	if err != nil {
+		return nil, err
 	}
-
-	httpResp, err := http.Get(url)
+	out, err := parsePreGames(body, sport)
 	if err != nil {
+		return nil, &ParseError{Task: "pre", Err: err}
+	}
+	return filterLeagues(ctx, out, "pre/"+sport), nil
+}
+
+// parsePreGames разбирает ответ task=pre (ключ games_pre, см. gamesRootKeys).
+func parsePreGames(body []byte, sport string) ([]Game, error) {
+	raw, err := gamesRoot(body, "pre", sport)
+	if raw == nil || err != nil {
 		return nil, err
 	}
-	defer httpResp.Body.Close()
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	// как и live — через map: смена типа одного поля (строка -> число) не ломает весь ответ
+	var arrRaw []any
+	if err := decodeJSON(raw, &arrRaw); err != nil {
 		return nil, err
 	}
 
 	var out []Game
-	for _, g := range resp.Games {
+	for _, item := range arrRaw {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		gameID := strField(m, "game_id")
+		if gameID == "" {
+			continue
+		}
 		out = append(out, Game{
-			GameID:     g.GameID,
+			GameID:     gameID,
 			Sport:      sport,
 			Bookmaker:  "bet365",
 			Source:     "pre",
-			League:     g.League,
-			Home:       g.Home,
-			Away:       g.Away,
-			Scores:     g.Scores,
-			TimeStatus: g.TimeStatus,
-			StartsAt:   parseUnixMaybe(g.Time),
+			League:     strField(m, "league"),
+			Home:       strField(m, "home"),
+			Away:       strField(m, "away"),
+			Scores:     strField(m, "scores"),
+			TimeStatus: normalizeTimeStatus(m["time_status"]),
+			StartsAt:   parseUnixMaybe(strField(m, "time")),
 		})
 	}
 	return out, nil
 }
 
-func fetchLiveGames(sport string) ([]Game, error) {
-	login := getEnv("API_LOGIN", "")
-	token := getEnv("API_TOKEN", "")
-	url := fmt.Sprintf("https://bookiesapi.com/api/get.php?login=%s&token=%s&task=live&bookmaker=bet365&sport=%s",
-		login, token, sport)
+func fetchLiveGames(ctx context.Context, sport string) ([]Game, error) {
+	defer logSlow(ctx, time.Now(), "fetch live", "sport="+sport)
+	reqURL := upstreamURL(url.Values{"task": {"live"}, "bookmaker": {"bet365"}, "sport": {sport}})
 
-	resp, err := http.Get(url)
+	body, err := upstreamFetch(ctx, reqURL, archiveMeta{Task: "live", Sport: sport})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	out, err := parseLiveGames(body, sport)
+	if err != nil {
+		return nil, &ParseError{Task: "live", Err: err}
+	}
+	return filterLeagues(ctx, out, "live/"+sport), nil
+}
 
-	var root map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+// parseLiveGames разбирает ответ task=live (ключ games, см. gamesRootKeys); для тенниса — счёт по сетам.
+func parseLiveGames(body []byte, sport string) ([]Game, error) {
+	raw, err := gamesRoot(body, "live", sport)
+	if raw == nil || err != nil {
 		return nil, err
 	}
-
-	arrRaw, ok := root["games"].([]any)
-	if !ok {
-		return nil, nil
+	var arrRaw []any
+	if err := decodeJSON(raw, &arrRaw); err != nil {
+		return nil, err
 	}
 
 	var out []Game
@@ -279,18 +533,30 @@ func fetchLiveGames(sport string) ([]Game, error) {
 		if !ok {
 			continue
 		}
-		gameID := fmt.Sprintf("%v", m["game_id"])
+		gameID := strField(m, "game_id")
+		if gameID == "" {
+			continue
+		}
+		scores := strField(m, "scores")
+		var detail *ScoreBoard
+		if sport == "tennis" {
+			if detail = parseTennisScores(m); detail != nil {
+				scores = detail.Display
+			}
+		}
 		out = append(out, Game{
 			GameID:     gameID,
 			Sport:      sport,
 			Bookmaker:  "bet365",
 			Source:     "live",
-			League:     fmt.Sprintf("%v", m["league"]),
-			Home:       fmt.Sprintf("%v", m["home"]),
-			Away:       fmt.Sprintf("%v", m["away"]),
-			Scores:     fmt.Sprintf("%v", m["scores"]),
-			TimeStatus: fmt.Sprintf("%v", m["time_status"]),
-			StartsAt:   parseUnixMaybe(fmt.Sprintf("%v", m["time"])),
+			League:     strField(m, "league"),
+			Home:       strField(m, "home"),
+			Away:       strField(m, "away"),
+			Scores:     scores,
+			TimeStatus: normalizeTimeStatus(m["time_status"]),
+			StartsAt:   parseUnixMaybe(strField(m, "time")),
+
+			ScoresDetail: detail,
 		})
 	}
 	return out, nil
@@ -298,171 +564,523 @@ func fetchLiveGames(sport string) ([]Game, error) {
 
 // --- LIVE ODDS FETCHING ---
 
-func fetchLiveGameIDs(pool *pgxpool.Pool) ([]string, error) {
-	rows, err := pool.Query(context.Background(), "SELECT game_id FROM games WHERE source='live' AND time_status='1'")
+// fetchPause — пауза между запросами коэффициентов по матчам в /update-liveodds:
+// FETCH_DELAY_MS плюс случайный джиттер до FETCH_JITTER_MS (по умолчанию половина задержки),
+// чтобы запросы не шли к апстриму ровной очередью подряд.
+// Это единственный троттлинг серийного цикла: отдельного rate limiter'а нет, а circuit breaker
+// лишь отсекает запросы во время отказа апстрима. Если появится параллельная загрузка с лимитером,
+// задержку стоит выставить в 0 — иначе паузы сложатся с ожиданием лимитера.
+func fetchPause() time.Duration {
+	delay := getEnvInt("FETCH_DELAY_MS", 0)
+	jitter := getEnvInt("FETCH_JITTER_MS", delay/2)
+	if jitter > 0 {
+		delay += rand.IntN(jitter)
+	}
+	return time.Duration(delay) * time.Millisecond
+}
+
+// Матчи, начавшиеся раньше LIVE_MAX_AGE_HOURS назад, считаем зависшими и не тратим на них запросы.
+func liveMaxAgeHours() int {
+	return getEnvInt("LIVE_MAX_AGE_HOURS", 6)
+}
+
+// fetchLiveGameIDs — текущие live-матчи; sport == "" — по всем видам спорта.
+func fetchLiveGameIDs(ctx context.Context, pool *pgxpool.Pool, sport string) ([]string, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT game_id FROM games
+		WHERE source='live' AND time_status='1'
+		  AND (starts_at IS NULL OR starts_at >= now() - make_interval(hours => $1))
+		  AND ($2 = '' OR sport = $2)`,
+		liveMaxAgeHours(), sport)
 	if err != nil {
-		return nil, err
+		return nil, dbError("fetch live game ids", err)
 	}
-	defer rows.Close()
+	ids, err := scanAll(rows, scanString)
+	return ids, dbError("fetch live game ids", err)
+}
 
-	var ids []string
-	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err == nil {
-			ids = append(ids, id)
+// expireStaleLiveGames переводит зависшие live-матчи в статус '3' (завершён) и закрывает их рынки.
+func expireStaleLiveGames(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	tag, err := pool.Exec(ctx, `
+		UPDATE games SET time_status='3', updated_at=clock_timestamp()
+		WHERE source='live' AND time_status='1'
+		  AND starts_at < now() - make_interval(hours => $1)`,
+		liveMaxAgeHours())
+	if err != nil {
+		return 0, err
+	}
+	if tag.RowsAffected() > 0 {
+		if _, err := closeEndedMarkets(ctx, pool); err != nil {
+			return 0, err
 		}
 	}
-	return ids, nil
+	return tag.RowsAffected(), nil
 }
 
-func getGameSport(pool *pgxpool.Pool, gameID string) (string, error) {
+func getGameSport(ctx context.Context, pool *pgxpool.Pool, gameID string) (string, error) {
 	var sport string
-	err := pool.QueryRow(context.Background(), "SELECT sport FROM games WHERE game_id=$1", gameID).Scan(&sport)
+	err := pool.QueryRow(ctx, "SELECT sport FROM games WHERE game_id=$1", gameID).Scan(&sport)
 	if err != nil {
 		return "", err
 	}
 	return sport, nil
 }
 
-func fetchLiveOdds(gameID, sport string) ([]LiveOdd, error) {
-	login := getEnv("API_LOGIN", "")
-	token := getEnv("API_TOKEN", "")
-	url := fmt.Sprintf("https://bookiesapi.com/api/get.php?login=%s&token=%s&task=liveodds&bookmaker=bet365&game_id=%s",
-		login, token, gameID)
+// bookmakers — букмекеры, у которых запрашиваются live-коэффициенты (BOOKMAKERS, через запятую).
+func bookmakers() []string {
+	return getEnvList("BOOKMAKERS", []string{"bet365"})
+}
 
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, err
+// fetchLiveOdds собирает коэффициенты и события матча по всем настроенным букмекерам.
+// Ошибка возвращается, только если не ответил ни один букмекер.
+// События у разных букмекеров повторяются — дубли отсекает insertIncidents.
+func fetchLiveOdds(ctx context.Context, gameID, sport string) ([]LiveOdd, []Incident, error) {
+	var all []LiveOdd
+	var incidents []Incident
+	var lastErr error
+	ok := 0
+	for _, bookmaker := range bookmakers() {
+		odds, evs, err := fetchBookmakerOdds(ctx, gameID, sport, bookmaker)
+		if err != nil {
+			logf(ctx, "❌ Fetch %s odds error for %s: %v", bookmaker, gameID, err)
+			lastErr = err
+			continue
+		}
+		ok++
+		odds, dropped := filterPrices(odds)
+		if dropped > 0 {
+			logf(ctx, "🚮 Dropped %d %s odds for %s outside %g..%g", dropped, bookmaker, gameID, priceMin, priceMax)
+		}
+		all = append(all, odds...)
+		incidents = append(incidents, evs...)
 	}
-	defer res.Body.Close()
+	if ok == 0 && lastErr != nil {
+		return nil, nil, lastErr
+	}
+	return all, incidents, nil
+}
 
+func fetchBookmakerOdds(ctx context.Context, gameID, sport, bookmaker string) ([]LiveOdd, []Incident, error) {
+	defer logSlow(ctx, time.Now(), "fetch liveodds", "sport="+sport+" game_id="+gameID+" bookmaker="+bookmaker)
+	reqURL := upstreamURL(url.Values{"task": {"liveodds"}, "bookmaker": {bookmaker}, "game_id": {gameID}})
+
+	body, err := upstreamFetch(ctx, reqURL, archiveMeta{Task: "liveodds", Sport: sport, GameID: gameID, Bookmaker: bookmaker})
+	if err != nil {
+		return nil, nil, err
+	}
 	var apiResp APIResponse
-	if err := json.NewDecoder(res.Body).Decode(&apiResp); err != nil {
-		return nil, err
+	if err := decodeJSON(body, &apiResp); err != nil {
+		return nil, nil, &ParseError{Task: "liveodds", Err: err}
+	}
+
+	odds, orphans := parseLiveOdds(apiResp, gameID, sport, bookmaker)
+	if orphans > 0 {
+		logf(ctx, "⚠️ Skipped %d selections before first market group for %s/%s", orphans, gameID, bookmaker)
+	}
+	if shared, dups := selectionIDAnomalies(odds); len(shared) > 0 || len(dups) > 0 {
+		// Ключ liveodds — (game_id, bookmaker, market_id, selection_id). Повтор внутри рынка схлопнет строки,
+		// а общий selection_id у разных рынков говорит, что на уникальность ID апстрима полагаться нельзя.
+		logf(ctx, "⚠️ Selection ID anomalies for %s/%s: shared across markets %v, duplicated within market %v",
+			gameID, bookmaker, shared, dups)
 	}
+	return odds, parseIncidents(apiResp, gameID), nil
+}
 
+// STORE_RAW=false — не хранить исходный PA в liveodds.raw. Сжимать его нет смысла: элемент ~110 байт
+// JSON, gzip даёт ~100 (≈10%), а TOAST в Postgres сжимает только значения от ~2 КБ. Отключение экономит
+// весь объём колонки; уже записанные raw очищаются при следующем обновлении исхода.
+var storeRaw = true
+
+// parseLiveOdds разбирает ответ liveodds: MG открывает группу рынка,
+// следующие за ним PA — исходы этого рынка. Чистая функция, без сети и БД.
+// PA до первого MG рынка не имеют — они пропускаются, их число возвращается вторым значением.
+func parseLiveOdds(apiResp APIResponse, gameID, sport, bookmaker string) ([]LiveOdd, int) {
 	var odds []LiveOdd
-	now := time.Now()
+	orphans := 0
 
 	var currentMarketID, currentMarketName string
 	for _, group := range apiResp.Results {
 		for _, item := range group {
-			switch fmt.Sprintf("%v", item["type"]) {
+			switch strField(item, "type") {
 			case "MG":
-				currentMarketID = fmt.Sprintf("%v", item["ID"])
-				currentMarketName = fmt.Sprintf("%v", item["NA"])
+				currentMarketID = strField(item, "ID")
+				currentMarketName = strField(item, "NA")
 			case "PA":
-				oddsStr, ok := getOddsField(item)
+				if currentMarketID == "" {
+					orphans++
+					continue
+				}
+				o, ok := parseSelection(item, sport, currentMarketName)
 				if !ok {
 					continue
 				}
-				priceDec, priceFrac, _ := fracToDecimal(oddsStr)
-				selectionID := fmt.Sprintf("%v", item["ID"])
-				selectionName := fmt.Sprintf("%v", item["NA"])
-				line := fmt.Sprintf("%v", item["HA"])
-				rawJSON, _ := json.Marshal(item)
-
-				odds = append(odds, LiveOdd{
-					GameID:        gameID,
-					Sport:         sport,
-					Bookmaker:     "bet365",
-					MarketID:      currentMarketID,
-					MarketName:    currentMarketName,
-					SelectionID:   selectionID,
-					SelectionName: selectionName,
-					Line:          line,
-					PriceDec:      priceDec,
-					PriceFrac:     priceFrac,
-					FetchedAt:     now,
-					Raw:           string(rawJSON),
-				})
+				o.GameID, o.Bookmaker = gameID, bookmaker
+				o.MarketID, o.MarketName, o.MarketKey = currentMarketID, currentMarketName, marketKey(currentMarketName)
+				setLine(&o)
+				if storeRaw {
+					rawJSON, _ := json.Marshal(item)
+					o.Raw = string(rawJSON)
+				}
+				odds = append(odds, o)
 			}
 		}
 	}
-	return odds, nil
+	setMarketShapes(odds)
+	return odds, orphans
+}
+
+// parseSelection — поля исхода из одного элемента PA: цена, название, линия, SU.
+// marketName нужен только для очистки названия; рынок и матч проставляет вызывающий. Тот же разбор использует /admin/reparse для сохранённых raw.
+func parseSelection(item map[string]any, sport, marketName string) (LiveOdd, bool) {
+	oddsStr, ok := getOddsField(item)
+	if !ok {
+		return LiveOdd{}, false
+	}
+	priceDec, priceFrac, _ := fracToDecimal(oddsStr)
+	return LiveOdd{
+		Sport:         sport,
+		SelectionID:   strField(item, "ID"),
+		SelectionName: cleanSelectionName(sport, marketName, strField(item, "NA")),
+		Line:          strField(item, "HA"),
+		PriceDec:      priceDec,
+		PriceFrac:     priceFrac,
+		IsSuspended:   isSuspended(item),
+	}, true
 }
 
 // --- DATABASE INSERTS ---
 
-func upsertGames(pool *pgxpool.Pool, games []Game) error {
+// withTx выполняет fn в транзакции: commit при успехе, rollback при любой ошибке.
+func withTx(ctx context.Context, pool *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // после Commit это no-op
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Максимум команд в одном SendBatch (BATCH_CHUNK_SIZE)
+var batchChunkSize = 500
+
+// execBatch отправляет батч частями по batchChunkSize команд и проверяет результат каждой.
+// Все части идут в одной транзакции tx, так что атомарность сохраняется.
+func execBatch(ctx context.Context, tx pgx.Tx, batch *pgx.Batch) error {
+	size := max(batchChunkSize, 1)
+	queued := batch.QueuedQueries
+	for start := 0; start < len(queued); start += size {
+		chunk := &pgx.Batch{QueuedQueries: queued[start:min(start+size, len(queued))]}
+		br := tx.SendBatch(ctx, chunk)
+		for i := 0; i < chunk.Len(); i++ {
+			if _, err := br.Exec(); err != nil {
+				br.Close()
+				return fmt.Errorf("batch command %d: %w", start+i, err)
+			}
+		}
+		if err := br.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertGames(ctx context.Context, pool *pgxpool.Pool, games []Game) error {
 	if len(games) == 0 {
 		return nil
 	}
+	defer logSlow(ctx, time.Now(), "upsert games", fmt.Sprintf("count=%d", len(games)))
+
+	games = mergeGames(games)
+
+	return withTxRetry(ctx, pool, "upsert games", func(tx pgx.Tx) error {
+		// Удаление матчей с прошедшей датой. Удалённые ID остаются в deleted_games (неделю),
+		// чтобы /api/games?since= мог сообщить клиенту, что матча больше нет.
+		_, err := tx.Exec(ctx, `
+			WITH gone AS (
+				DELETE FROM games
+				WHERE starts_at < CURRENT_DATE
+				RETURNING game_id
+			), pruned AS (
+				DELETE FROM deleted_games WHERE deleted_at < now() - INTERVAL '7 days'
+			)
+			INSERT INTO deleted_games (game_id, deleted_at)
+			SELECT game_id, clock_timestamp() FROM gone
+			ON CONFLICT (game_id) DO UPDATE SET deleted_at = EXCLUDED.deleted_at
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to delete old games: %w", err)
+		}
 
-	// Удаление матчей с прошедшей датой
-	_, err := pool.Exec(context.Background(), `
-		DELETE FROM games
-		WHERE starts_at < CURRENT_DATE
-	`)
+		if err := execBatch(ctx, tx, gamesBatch(games)); err != nil {
+			return err
+		}
+		if err := markMissingGames(ctx, tx, games); err != nil {
+			return fmt.Errorf("failed to mark missing games: %w", err)
+		}
+		_, err = closeEndedMarkets(ctx, tx)
+		return err
+	})
+}
+
+// Сколько синков подряд активный матч может отсутствовать в фиде, прежде чем
+// считаться пропавшим (отменён/снят апстримом без прошедшей даты) и скрыться из /api/games.
+func missingSyncsLimit() int {
+	return max(getEnvInt("GAME_MISSING_SYNCS", 3), 1)
+}
+
+// markMissingGames обнуляет missed_syncs у пришедших матчей и увеличивает у отсутствующих.
+// Считаются только фиды (source + sport), которые в этом синке что-то вернули: упавший
+// или пустой фид не должен разом «потерять» все свои матчи.
+func markMissingGames(ctx context.Context, tx pgx.Tx, games []Game) error {
+	ids := make([]string, 0, len(games))
+	type feed struct{ source, sport string }
+	seen := map[feed]bool{}
+	var sources, feedSports []string
+	for _, g := range games {
+		ids = append(ids, g.GameID)
+		if f := (feed{g.Source, g.Sport}); !seen[f] {
+			seen[f] = true
+			sources = append(sources, g.Source)
+			feedSports = append(feedSports, g.Sport)
+		}
+	}
+
+	// updated_at двигаем, когда матч пропадает из /api/games (missed_syncs доходит до лимита)
+	// и когда возвращается: так ?since= видит и скрытие, и возврат
+	limit, gone := missingSyncsLimit(), 0
+	rows, err := tx.Query(ctx, `
+		UPDATE games
+		SET missed_syncs = CASE WHEN game_id = ANY($1) THEN 0 ELSE missed_syncs + 1 END,
+		    updated_at = CASE
+		        WHEN game_id = ANY($1) AND missed_syncs >= $5 THEN clock_timestamp()
+		        WHEN NOT game_id = ANY($1) AND missed_syncs + 1 = $5 THEN clock_timestamp()
+		        ELSE updated_at END
+		WHERE (game_id = ANY($1) AND missed_syncs > 0)
+		   OR (NOT game_id = ANY($1) AND time_status = ANY($4)
+		       AND (source, sport) IN (SELECT * FROM unnest($2::text[], $3::text[])))
+		RETURNING missed_syncs`, ids, sources, feedSports, activeTimeStatuses, limit)
 	if err != nil {
-		return fmt.Errorf("failed to delete old games: %w", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var missed int
+		if err := rows.Scan(&missed); err != nil {
+			return err
+		}
+		if missed == limit {
+			gone++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if gone > 0 {
+		logf(ctx, "👻 %d games missing from feed for %d syncs, hidden from /api/games", gone, limit)
+	}
+	return nil
+}
+
+// mergeGames оставляет одну запись на game_id. Матч может прийти и в pre, и в live
+// (переход в лайв между запросами) — live-запись всегда побеждает pre.
+// В БД то же правило держит WHERE в ON CONFLICT: устаревший pre не откатит live.
+func mergeGames(games []Game) []Game {
+	idx := make(map[string]int, len(games))
+	out := make([]Game, 0, len(games))
+	for _, g := range games {
+		i, seen := idx[g.GameID]
+		if !seen {
+			idx[g.GameID] = len(out)
+			out = append(out, g)
+			continue
+		}
+		if g.Source == "live" || out[i].Source != "live" {
+			out[i] = g
+		}
 	}
+	return out
+}
 
+// gamesBatch: если у live-матча нет времени начала, starts_at = now() с флагом
+// starts_at_estimated, чтобы он не уезжал в конец сортировки по времени. Однажды
+// проставленная оценка не сдвигается на следующих синках; реальное время её заменяет.
+func gamesBatch(games []Game) *pgx.Batch {
 	// Продолжение: вставка обновленных данных
+	// Неизменившийся матч не обновляется (IS DISTINCT FROM): иначе каждый синк двигал бы
+	// updated_at всем матчам и ?since= отдавал бы полный список. updated_at — clock_timestamp(),
+	// а не now() (начало транзакции синка), см. gamesServerTime.
 	batch := &pgx.Batch{}
 	for _, g := range games {
 		batch.Queue(`
 			INSERT INTO games
-				(game_id, sport, bookmaker, source, league, home_team, away_team, scores, time_status, starts_at, updated_at)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,now())
+				(game_id, sport, bookmaker, source, league, home_team, away_team, scores, time_status, starts_at, scores_detail,
+				 starts_at_estimated, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,
+				COALESCE($10, CASE WHEN $12 THEN now() END), $11,
+				$10 IS NULL AND $12, clock_timestamp())
 			ON CONFLICT (game_id)
 			DO UPDATE SET
-				sport=$2, bookmaker=$3, source=$4, league=$5, home_team=$6, away_team=$7, scores=$8, time_status=$9, starts_at=$10, updated_at=now()
-		`, g.GameID, g.Sport, g.Bookmaker, g.Source, g.League, g.Home, g.Away, g.Scores, g.TimeStatus, g.StartsAt)
+				sport=$2, bookmaker=$3, source=$4, league=$5, home_team=$6, away_team=$7, scores=$8, time_status=$9,
+				starts_at=COALESCE($10, games.starts_at, CASE WHEN $12 THEN now() END),
+				starts_at_estimated=CASE WHEN $10 IS NOT NULL THEN false
+					ELSE games.starts_at_estimated OR (games.starts_at IS NULL AND $12) END,
+				scores_detail=$11, updated_at=clock_timestamp()
+			WHERE NOT (games.source = 'live' AND EXCLUDED.source = 'pre')
+			  AND (games.sport, games.bookmaker, games.source, games.league, games.home_team, games.away_team,
+			       games.scores, games.time_status, games.starts_at, games.starts_at_estimated, games.scores_detail)
+			      IS DISTINCT FROM
+			      ($2, $3, $4, $5, $6, $7, $8, $9,
+			       COALESCE($10, games.starts_at, CASE WHEN $12 THEN now() END),
+			       CASE WHEN $10 IS NOT NULL THEN false
+			            ELSE games.starts_at_estimated OR (games.starts_at IS NULL AND $12) END,
+			       $11::jsonb)
+		`, g.GameID, g.Sport, g.Bookmaker, g.Source, g.League, g.Home, g.Away, g.Scores, g.TimeStatus, g.StartsAt, g.ScoresDetail,
+			g.Source == "live" && g.TimeStatus == "1")
 	}
-
-	br := pool.SendBatch(context.Background(), batch)
-	defer br.Close()
-	for range games {
-		if _, err := br.Exec(); err != nil {
-			return err
-		}
-	}
-	return nil
+	return batch
 }
 
-func insertLiveOdds(pool *pgxpool.Pool, odds []LiveOdd) error {
+func insertLiveOdds(ctx context.Context, pool *pgxpool.Pool, odds []LiveOdd) error {
 	if len(odds) == 0 {
 		return nil
 	}
+	defer logSlow(ctx, time.Now(), "insert liveodds", fmt.Sprintf("game_id=%s count=%d", odds[0].GameID, len(odds)))
 
-	// Удаление устаревших коэффициентов (например, старше 1 дня)
-	_, err := pool.Exec(context.Background(), `
-		DELETE FROM liveodds
-		WHERE fetched_at < NOW() - INTERVAL '1 day'
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to delete old live odds: %w", err)
-	}
+	return withTxRetry(ctx, pool, "insert liveodds", func(tx pgx.Tx) error {
+		// Удаление устаревших коэффициентов (например, старше 1 дня)
+		_, err := tx.Exec(ctx, `
+			DELETE FROM liveodds
+			WHERE fetched_at < NOW() - INTERVAL '1 day'
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to delete old live odds: %w", err)
+		}
 
+		if err := execBatch(ctx, tx, liveOddsBatch(odds)); err != nil {
+			return err
+		}
+		return closeMissingMarkets(ctx, tx, odds)
+	})
+}
+
+// liveOddsBatch: fetched_at ставит БД (now()), как и в очистке по возрасту и в price_events,
+// поэтому расхождение часов приложения и БД сравнения не ломает. now() фиксируется на старте
+// транзакции, так что у всех исходов одного ответа фида одинаковый fetched_at.
+func liveOddsBatch(odds []LiveOdd) *pgx.Batch {
 	batch := &pgx.Batch{}
 	for _, o := range odds {
+		// Сначала фиксируем изменение цены относительно сохранённой, потом обновляем саму цену
+		batch.Queue(`
+			INSERT INTO price_events
+				(game_id, bookmaker, market_id, selection_id, selection_name, old_price, new_price, delta, ts)
+			SELECT game_id, bookmaker, market_id, selection_id, $5,
+			       price_dec, NULLIF($6, '')::numeric, NULLIF($6, '')::numeric - price_dec, now()
+			FROM liveodds
+			WHERE game_id=$1 AND bookmaker=$2 AND market_id=$3 AND selection_id=$4
+			  AND abs(price_dec - NULLIF($6, '')::numeric) > $7::numeric
+		`, o.GameID, o.Bookmaker, o.MarketID, o.SelectionID, o.SelectionName, o.PriceDec, priceEpsilon)
+
 		batch.Queue(`
 			INSERT INTO liveodds
 				(game_id, sport, bookmaker, market_id, market_name,
 				 selection_id, selection_name, line, price_dec, price_frac,
-				 fetched_at, raw)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
-			ON CONFLICT (game_id, market_id, selection_id)
+				 fetched_at, raw, selection_count, market_shape, market_key, opening_price_dec, is_suspended, status, line_type, first_seen)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NULLIF($9, '')::numeric,$10,now(),$11,$12,$13,$14,NULLIF($9, '')::numeric,$15,$16,$19,now())
+			ON CONFLICT (game_id, bookmaker, market_id, selection_id)
 			DO UPDATE SET
-				sport=$2, bookmaker=$3, market_name=$5, selection_name=$7,
-				line=$8, price_dec=$9, price_frac=$10, fetched_at=$11, raw=$12
+				sport=$2, market_name=$5, selection_name=$7,
+				line=$8, price_dec=NULLIF($9, '')::numeric, price_frac=$10, fetched_at=now(), raw=$11,
+				selection_count=$12, market_shape=$13, market_key=$14, is_suspended=$15, status=$16, line_type=$19
+			WHERE abs(liveodds.price_dec - NULLIF($9, '')::numeric) > $17::numeric
+			   OR (liveodds.price_dec IS NULL) <> (NULLIF($9, '') IS NULL)
+			   OR (liveodds.line, liveodds.is_suspended, liveodds.status, liveodds.selection_name, liveodds.market_name,
+			       liveodds.selection_count, liveodds.market_shape, liveodds.market_key, liveodds.line_type)
+			      IS DISTINCT FROM ($8, $15, $16, $7, $5, $12, $13, $14, $19)
+			   OR liveodds.fetched_at < now() - make_interval(secs => $18)
 		`, o.GameID, o.Sport, o.Bookmaker, o.MarketID, o.MarketName,
 			o.SelectionID, o.SelectionName, o.Line, o.PriceDec, o.PriceFrac,
-			o.FetchedAt, o.Raw)
+			o.Raw, o.SelectionCount, o.MarketShape, o.MarketKey, o.IsSuspended, marketStatus(o.IsSuspended),
+			priceEpsilon, oddsTouchInterval.Seconds(), o.LineType)
 	}
+	return batch
+}
 
-	br := pool.SendBatch(context.Background(), batch)
-	defer br.Close()
-	for range odds {
-		if _, err := br.Exec(); err != nil {
-			return err
-		}
+// --- HELPERS ---
+
+// logSlow предупреждает, если вызов длился дольше SLOW_THRESHOLD_MS (0 — отключено).
+// Использование: defer logSlow(ctx, time.Now(), "fetch pre", "sport=soccer")
+func logSlow(ctx context.Context, start time.Time, op, details string) {
+	threshold := getEnvInt("SLOW_THRESHOLD_MS", 2000)
+	if threshold <= 0 {
+		return
+	}
+	if d := time.Since(start); d > time.Duration(threshold)*time.Millisecond {
+		logf(ctx, "🐢 Slow %s (%s): %s", op, details, d.Round(time.Millisecond))
+	}
+}
+
+// decodeJSON — json.Unmarshal с UseNumber: числа в map[string]any остаются json.Number
+// с исходным текстом. Через float64 ID больше 2^53 (game_id, ID исхода) теряли бы младшие цифры.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after top-level JSON value")
 	}
 	return nil
 }
 
-// --- HELPERS ---
+// strField — строковое значение поля JSON-объекта апстрима. Отсутствующее поле и null дают "",
+// а не "<nil>" от fmt; числа — ровно как в ответе (json.Number, см. decodeJSON), без экспоненты.
+func strField(m map[string]any, key string) string {
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// normalizeTimeStatus приводит time_status к канонической строке ("0", "1", "3", ...),
+// в каком бы виде его ни прислал апстрим: "1", " 1 ", 1, 1.0, "1.0". Фильтры в SQL
+// (activeTimeStatuses, fetchLiveGameIDs) сравнивают именно с такой строкой.
+// Нечисловые значения остаются как есть (без пробелов), отсутствующее поле — "".
+func normalizeTimeStatus(v any) string {
+	var s string
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		s = strings.TrimSpace(t)
+	case float64:
+		if t == math.Trunc(t) {
+			return strconv.FormatInt(int64(t), 10)
+		}
+		s = strconv.FormatFloat(t, 'f', -1, 64)
+	case json.Number:
+		s = t.String()
+	default:
+		s = strings.TrimSpace(fmt.Sprint(t))
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f == math.Trunc(f) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return s
+}
 
 func parseUnixMaybe(s string) *time.Time {
 	s = strings.TrimSpace(s)
@@ -488,15 +1106,198 @@ func fracToDecimal(odds string) (string, string, bool) {
 	if err1 != nil || err2 != nil || b == 0 {
 		return "", odds, false
 	}
-	d := 1.0 + (a / b)
-	d = math.Round(d*1000) / 1000
+	d := roundPrice(1.0 + (a / b))
 	return strconv.FormatFloat(d, 'f', -1, 64), odds, true
 }
 
+// Знаков после запятой в десятичных коэффициентах (ODDS_DECIMAL_PLACES, -1 — без округления)
+var oddsDecimalPlaces = 3
+
+// Режим округления цен (ODDS_ROUNDING): half_up — половина от нуля (2.0125 → 2.013),
+// bankers — половина к чётному (2.0125 → 2.012, 2.0135 → 2.014).
+const (
+	RoundHalfUp  = "half_up"
+	RoundBankers = "bankers"
+)
+
+var oddsRounding = RoundHalfUp
+
+// roundPrice — единственное место округления цен (конвертация дробей, movement, smoothed, snapshot-diff).
+// Сдвиг запятой делается в десятичной записи, а не умножением: 2.0125*1000 во float64 —
+// 2012.4999…, и граница .xxx5 округлялась бы вниз в обоих режимах.
+func roundPrice(d float64) float64 {
+	if oddsDecimalPlaces < 0 {
+		return d
+	}
+	mant, exp, _ := strings.Cut(strconv.FormatFloat(d, 'e', -1, 64), "e")
+	e, _ := strconv.Atoi(exp)
+	scaled, err := strconv.ParseFloat(mant+"e"+strconv.Itoa(e+oddsDecimalPlaces), 64)
+	if err != nil {
+		scaled = d * math.Pow10(oddsDecimalPlaces)
+	}
+	if oddsRounding == RoundBankers {
+		scaled = math.RoundToEven(scaled)
+	} else {
+		scaled = math.Round(scaled)
+	}
+	return scaled / math.Pow10(oddsDecimalPlaces)
+}
+
+// Правдоподобный диапазон десятичной цены (PRICE_MIN / PRICE_MAX); всё вне его — мусор апстрима.
+var priceMin, priceMax = 1.01, 1000.0
+
+// Порог записи коэффициентов: если цена сдвинулась не больше чем на priceEpsilon (PRICE_EPSILON)
+// и остальные поля исхода те же, строка liveodds не перезаписывается. fetched_at при этом
+// освежается не чаще раза в oddsTouchInterval (ODDS_TOUCH_INTERVAL) — по нему чистятся старые строки.
+var (
+	priceEpsilon      = 0.0
+	oddsTouchInterval = 5 * time.Minute
+)
+
+// filterPrices отбрасывает исходы с ценой вне [priceMin, priceMax], границы включительно.
+// Исходы без десятичной цены (дробь не распарсилась) остаются: они пишутся с NULL.
+func filterPrices(odds []LiveOdd) ([]LiveOdd, int) {
+	out := odds[:0]
+	for _, o := range odds {
+		if o.PriceDec != "" {
+			if d, err := strconv.ParseFloat(o.PriceDec, 64); err == nil && (d < priceMin || d > priceMax) {
+				continue
+			}
+		}
+		out = append(out, o)
+	}
+	return out, len(odds) - len(out)
+}
+
+// selectionIDAnomalies проверяет допущения ключа liveodds для одного ответа фида:
+// shared — selection_id, встречающиеся в нескольких market_id (selection_id -> рынки),
+// dups — пары market_id/selection_id, пришедшие больше одного раза.
+func selectionIDAnomalies(odds []LiveOdd) (map[string][]string, []string) {
+	markets := map[string][]string{}
+	seen := map[[2]string]int{}
+	for _, o := range odds {
+		k := [2]string{o.MarketID, o.SelectionID}
+		if seen[k]++; seen[k] == 1 {
+			markets[o.SelectionID] = append(markets[o.SelectionID], o.MarketID)
+		}
+	}
+
+	shared := map[string][]string{}
+	for sel, ms := range markets {
+		if len(ms) > 1 {
+			shared[sel] = ms
+		}
+	}
+	var dups []string
+	for k, n := range seen {
+		if n > 1 {
+			dups = append(dups, k[0]+"/"+k[1])
+		}
+	}
+	slices.Sort(dups)
+	return shared, dups
+}
+
+// setMarketShapes считает исходы в каждой группе MG и проставляет форму рынка:
+// у тенниса победитель матча — 2 исхода (h2h), у футбола — 3 (1x2).
+func setMarketShapes(odds []LiveOdd) {
+	counts := map[string]int{}
+	for _, o := range odds {
+		counts[o.MarketID]++
+	}
+	for i := range odds {
+		n := counts[odds[i].MarketID]
+		odds[i].SelectionCount = n
+		odds[i].MarketShape = marketShape(n)
+	}
+}
+
+func marketShape(n int) string {
+	switch n {
+	case 2:
+		return "h2h"
+	case 3:
+		return "1x2"
+	default:
+		return "multi"
+	}
+}
+
+// Канонические ключи основных футбольных рынков; всё остальное — "other".
+const (
+	MarketKey1X2    = "1x2"
+	MarketKeyTotals = "totals"
+	MarketKeyBTTS   = "btts"
+	MarketKeyOther  = "other"
+)
+
+func marketKey(name string) string {
+	n := strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case n == "fulltime result", n == "full time result", n == "match result", n == "1x2":
+		return MarketKey1X2
+	case strings.Contains(n, "both teams to score"):
+		return MarketKeyBTTS
+	case strings.Contains(n, "over/under"), strings.Contains(n, "total goals"), n == "match goals", n == "goal line":
+		return MarketKeyTotals
+	default:
+		return MarketKeyOther
+	}
+}
+
+// Смысл поля HA (line) зависит от рынка
+const (
+	LineHandicap = "handicap" // фора команды: "-1.5", "+0.5,+1.0" (азиатская, разбитая)
+	LineTotal    = "total"    // граница тотала: "2.5"
+	LineOther    = "other"    // HA есть, но рынок не распознан — строка как пришла
+	LineNone     = "none"     // у рынка линии нет (исход матча, обе забьют); HA игнорируется
+)
+
+// lineType определяет, что означает HA для рынка.
+func lineType(marketName, ha string) string {
+	n := strings.ToLower(marketName)
+	switch {
+	case strings.Contains(n, "handicap"), strings.Contains(n, "spread"), strings.Contains(n, "run line"), strings.Contains(n, "puck line"):
+		return LineHandicap
+	case marketKey(marketName) == MarketKeyTotals, strings.Contains(n, "total"), strings.Contains(n, "over/under"), strings.Contains(n, "goal line"):
+		return LineTotal
+	case marketKey(marketName) == MarketKey1X2, marketKey(marketName) == MarketKeyBTTS, strings.TrimSpace(ha) == "",
+		strings.Contains(n, "result"), strings.Contains(n, "to win"), strings.Contains(n, "winner"),
+		strings.Contains(n, "double chance"), strings.Contains(n, "draw no bet"):
+		return LineNone
+	default:
+		return LineOther
+	}
+}
+
+// setLine проставляет LineType по рынку исхода; для рынков без линии Line очищается.
+func setLine(o *LiveOdd) {
+	o.LineType = lineType(o.MarketName, o.Line)
+	if o.LineType == LineNone {
+		o.Line = ""
+	}
+}
+
+// isSuspended — bet365 помечает закрытые для ставок исходы флагом "SU":"1".
+func isSuspended(item map[string]any) bool {
+	switch v := item["SU"].(type) {
+	case string:
+		return v == "1" || strings.EqualFold(v, "true")
+	case float64:
+		return v == 1
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 1
+	case bool:
+		return v
+	}
+	return false
+}
+
 func getOddsField(item map[string]any) (string, bool) {
 	for _, key := range []string{"OD", "ODD", "ODDS"} {
-		if v, ok := item[key]; ok {
-			return fmt.Sprintf("%v", v), true
+		if _, ok := item[key]; ok {
+			return strField(item, key), true
 		}
 	}
 	return "", false
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	_ "time/tzdata" // в alpine-образе нет зон, а MAINTENANCE_TZ может быть не UTC
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- MAINTENANCE WINDOWS ---
+// MAINTENANCE_WINDOWS=02:00-02:30,23:50-00:10 — ежедневные окна, когда апстрим на обслуживании
+// и синки не запускаются. Окно может переходить через полночь. Время — в MAINTENANCE_TZ (UTC).
+
+type maintenanceWindow struct {
+	from, to int // минуты от начала суток
+}
+
+func (w maintenanceWindow) contains(minute int) bool {
+	if w.from <= w.to {
+		return minute >= w.from && minute < w.to
+	}
+	return minute >= w.from || minute < w.to
+}
+
+func (w maintenanceWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.from/60, w.from%60, w.to/60, w.to%60)
+}
+
+var (
+	maintenanceWindows []maintenanceWindow
+	maintenanceLoc     = time.UTC
+)
+
+func parseMaintenanceWindows(specs []string) ([]maintenanceWindow, error) {
+	var out []maintenanceWindow
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, fmt.Errorf("window %q: expected HH:MM-HH:MM", spec)
+		}
+		f, err := parseClock(from)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", spec, err)
+		}
+		t, err := parseClock(to)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", spec, err)
+		}
+		if f == t {
+			return nil, fmt.Errorf("window %q: empty range", spec)
+		}
+		out = append(out, maintenanceWindow{from: f, to: t})
+	}
+	return out, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// inMaintenance возвращает текущее окно обслуживания, если оно идёт сейчас.
+func inMaintenance(now time.Time) (maintenanceWindow, bool) {
+	local := now.In(maintenanceLoc)
+	minute := local.Hour()*60 + local.Minute()
+	for _, w := range maintenanceWindows {
+		if w.contains(minute) {
+			return w, true
+		}
+	}
+	return maintenanceWindow{}, false
+}
+
+// maintenanceGuard отвечает 503 на запуск синка во время окна обслуживания,
+// чтобы не жечь запросы к апстриму, который всё равно отвечает ошибками.
+func maintenanceGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if w, ok := inMaintenance(time.Now()); ok {
+			logf(c.Request.Context(), "🛠️ %s skipped: maintenance window %s", c.Request.URL.Path, w)
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":  "maintenance",
+				"window": w.String(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func maintenanceStats() gin.H {
+	windows := make([]string, len(maintenanceWindows))
+	for i, w := range maintenanceWindows {
+		windows[i] = w.String()
+	}
+	resp := gin.H{"active": false, "windows": windows, "tz": maintenanceLoc.String()}
+	if w, ok := inMaintenance(time.Now()); ok {
+		resp["active"] = true
+		resp["current"] = w.String()
+	}
+	return resp
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// --- MARKET STATUS ---
+// open      — рынок в фиде, ставки принимаются
+// suspended — рынок в фиде, но исход закрыт флагом SU
+// closed    — матч завершён или рынок пропал из фида; по завершённым матчам коэффициенты больше не запрашиваются
+
+const (
+	MarketOpen      = "open"
+	MarketSuspended = "suspended"
+	MarketClosed    = "closed"
+)
+
+// time_status апстрима, после которых матч уже не вернётся в лайв:
+// 3 ended, 5 cancelled, 6 walkover, 8 abandoned, 9 retired, 99 removed.
+// 4 (postponed) и 7 (interrupted) не закрываем — матч может продолжиться.
+var closedTimeStatuses = []string{"3", "5", "6", "8", "9", "99"}
+
+func marketStatus(suspended bool) string {
+	if suspended {
+		return MarketSuspended
+	}
+	return MarketOpen
+}
+
+// execer — общее у pgxpool.Pool и pgx.Tx, чтобы закрывать рынки и в транзакции, и без неё.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// closeEndedMarkets закрывает рынки всех матчей в финальном time_status.
+func closeEndedMarkets(ctx context.Context, db execer) (int64, error) {
+	tag, err := db.Exec(ctx, `
+		UPDATE liveodds l SET status = 'closed'
+		FROM games g
+		WHERE g.game_id = l.game_id AND g.time_status = ANY($1) AND l.status <> 'closed'`,
+		closedTimeStatuses)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// closeMissingMarkets закрывает рынки матча у букмекера, которых не было в последнем ответе фида.
+// Если рынок вернётся, следующий upsert снова откроет его.
+func closeMissingMarkets(ctx context.Context, db execer, odds []LiveOdd) error {
+	type key struct{ game, bookmaker string }
+	seen := map[key][]string{}
+	for _, o := range odds {
+		k := key{o.GameID, o.Bookmaker}
+		seen[k] = append(seen[k], o.MarketID)
+	}
+	for k, markets := range seen {
+		if _, err := db.Exec(ctx, `
+			UPDATE liveodds SET status = 'closed'
+			WHERE game_id = $1 AND bookmaker = $2 AND market_id <> ALL($3) AND status <> 'closed'`,
+			k.game, k.bookmaker, markets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
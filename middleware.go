@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- MIDDLEWARE ---
+
+// ginMode: GIN_MODE, если задан явно; иначе debug только для APP_ENV=dev, в остальных случаях release.
+func ginMode() string {
+	if mode := getEnv("GIN_MODE", ""); mode != "" {
+		return mode
+	}
+	switch getEnv("APP_ENV", "") {
+	case "dev", "development", "local":
+		return gin.DebugMode
+	default:
+		return gin.ReleaseMode
+	}
+}
+
+// requestLogger — одна строка key=value на запрос вместо стандартного логгера gin.
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		logf(c.Request.Context(), "http method=%s path=%s status=%d duration=%s ip=%s size=%d",
+			c.Request.Method, c.Request.URL.Path, c.Writer.Status(),
+			time.Since(start).Round(time.Microsecond), c.ClientIP(), c.Writer.Size())
+	}
+}
+
+type requestIDKey struct{}
+
+// requestIDPattern — какой входящий X-Request-ID принимаем как есть; остальные заменяем своим,
+// чтобы в логи не попадали произвольные строки клиента.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestID берёт X-Request-ID из запроса или генерирует новый, кладёт его в контекст
+// запроса и возвращает в ответе. Всё, что логируется через logf с этим контекстом, помечается req_id.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header("X-Request-ID", id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDKey{}, id))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// logf — log.Printf с префиксом req_id=..., если в ctx есть request ID.
+func logf(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		msg = "req_id=" + id + " " + msg
+	}
+	log.Print(msg)
+}
+
+// bodyLimit режет тела запросов больше maxBytes: по Content-Length сразу 413,
+// а при чтении без заголовка длины MaxBytesReader вернёт ошибку хендлеру.
+func bodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// requestTimeout ограничивает контекст запроса. Хендлеры передают c.Request.Context()
+// в запросы к БД, так что по дедлайну запрос прерывается и serverError отдаёт 408.
+func requestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusRequestTimeout, gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// serverError — ответ на ошибку хендлера: 413/408 для превышения лимитов, 503 при остановке, 502 для отказа апстрима, иначе 500.
+func serverError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	var upstreamErr *UpstreamError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusRequestTimeout, gin.H{"error": "request timed out"})
+	case errors.Is(err, errShuttingDown):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.As(err, &upstreamErr):
+		// синк упал на стороне апстрима — это не наша 500
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "kind": "upstream"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// isAdmin проверяет токен из X-Admin-Token или Authorization: Bearer против ADMIN_TOKEN.
+// Без ADMIN_TOKEN админские возможности выключены полностью.
+func isAdmin(c *gin.Context) bool {
+	want := getEnv("ADMIN_TOKEN", "")
+	if want == "" {
+		return false
+	}
+	got := c.GetHeader("X-Admin-Token")
+	if got == "" {
+		got = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func adminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin token required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- MOVEMENT ---
+
+type MovementView struct {
+	Bookmaker     string   `json:"bookmaker"`
+	MarketID      string   `json:"market_id"`
+	MarketName    string   `json:"market_name"`
+	SelectionID   string   `json:"selection_id"`
+	SelectionName string   `json:"selection_name"`
+	HasOpening    bool     `json:"has_opening"`
+	Opening       *float64 `json:"opening,omitempty"`
+	Current       *float64 `json:"current,omitempty"`
+	Change        *float64 `json:"change,omitempty"`
+	ChangePct     *float64 `json:"change_pct,omitempty"`
+	Direction     string   `json:"direction,omitempty"` // shortened / drifted / unchanged
+}
+
+// GET /api/games/:id/movement — текущая цена каждого исхода против цены открытия.
+func gameMovementHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT bookmaker, market_id, market_name, selection_id, selection_name,
+			       opening_price_dec::float8, price_dec::float8
+			FROM liveodds
+			WHERE game_id = $1
+			ORDER BY market_id, selection_id, bookmaker`, c.Param("id"))
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []MovementView{}
+		for rows.Next() {
+			var m MovementView
+			if err := rows.Scan(&m.Bookmaker, &m.MarketID, &m.MarketName, &m.SelectionID, &m.SelectionName, &m.Opening, &m.Current); err != nil {
+				serverError(c, err)
+				return
+			}
+			m.HasOpening = m.Opening != nil
+			if m.Opening != nil && m.Current != nil {
+				change := roundPrice(*m.Current - *m.Opening)
+				pct := math.Round(change / *m.Opening * 10000) / 100
+				m.Change, m.ChangePct = &change, &pct
+				switch {
+				case change < 0:
+					m.Direction = "shortened"
+				case change > 0:
+					m.Direction = "drifted"
+				default:
+					m.Direction = "unchanged"
+				}
+			}
+			out = append(out, m)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		respondList(c, "movement", out, len(out), len(out), 0, gin.H{"game_id": c.Param("id")})
+	}
+}
+
+// --- SMOOTHED PRICES ---
+
+type SmoothedView struct {
+	Bookmaker     string   `json:"bookmaker"`
+	MarketID      string   `json:"market_id"`
+	MarketName    string   `json:"market_name"`
+	SelectionID   string   `json:"selection_id"`
+	SelectionName string   `json:"selection_name"`
+	Latest        *float64 `json:"latest"`
+	Smoothed      *float64 `json:"smoothed"`
+	Samples       int      `json:"samples"` // сколько точек истории попало в окно
+}
+
+// GET /api/games/:id/smoothed?window=5 — сглаженная цена исхода по последним N значениям.
+// История — price_events, одна строка на изменение цены (неизменившаяся цена не пишется).
+// window — сколько последних значений цены (изменений) берётся; каждое взвешивается
+// временем, которое оно держалось: до следующего изменения, у последнего — до сейчас.
+// Так цена, простоявшая десять загрузок, весит как десять загрузок, а мигнувшая на одну — как одна,
+// то есть это среднее по загрузкам за период окна. Samples — число значений в окне.
+// Без истории smoothed = latest.
+func gameSmoothedHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		window, err := queryInt(c, "window", 5, 1, 50)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			WITH hist AS (
+				SELECT bookmaker, market_id, selection_id, new_price,
+				       extract(epoch FROM COALESCE(lead(ts) OVER w, now()) - ts) AS held,
+				       row_number() OVER (PARTITION BY bookmaker, market_id, selection_id ORDER BY ts DESC, id DESC) AS rn
+				FROM price_events
+				WHERE game_id = $1
+				WINDOW w AS (PARTITION BY bookmaker, market_id, selection_id ORDER BY ts, id)
+			)
+			SELECT l.bookmaker, l.market_id, l.market_name, l.selection_id, l.selection_name,
+			       l.price_dec::float8,
+			       COALESCE(sum(h.new_price * h.held) / NULLIF(sum(h.held), 0), avg(h.new_price))::float8,
+			       count(h.new_price)
+			FROM liveodds l
+			LEFT JOIN hist h
+			       ON h.bookmaker = l.bookmaker AND h.market_id = l.market_id
+			      AND h.selection_id = l.selection_id AND h.rn <= $2
+			WHERE l.game_id = $1
+			GROUP BY l.bookmaker, l.market_id, l.market_name, l.selection_id, l.selection_name, l.price_dec
+			ORDER BY l.market_id, l.selection_id, l.bookmaker`, c.Param("id"), window)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []SmoothedView{}
+		for rows.Next() {
+			var s SmoothedView
+			if err := rows.Scan(&s.Bookmaker, &s.MarketID, &s.MarketName, &s.SelectionID, &s.SelectionName,
+				&s.Latest, &s.Smoothed, &s.Samples); err != nil {
+				serverError(c, err)
+				return
+			}
+			if s.Smoothed != nil {
+				v := roundPrice(*s.Smoothed)
+				s.Smoothed = &v
+			} else {
+				s.Smoothed = s.Latest
+			}
+			out = append(out, s)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		respondList(c, "smoothed", out, len(out), len(out), 0, gin.H{"game_id": c.Param("id"), "window": window})
+	}
+}
+
+// --- SNAPSHOT DIFF ---
+
+type SnapshotDiffView struct {
+	Bookmaker     string     `json:"bookmaker"`
+	MarketID      string     `json:"market_id"`
+	MarketName    string     `json:"market_name"`
+	SelectionID   string     `json:"selection_id"`
+	SelectionName string     `json:"selection_name"`
+	Latest        *float64   `json:"latest"`
+	AtPrice       *float64   `json:"at_price"`
+	AtTS          *time.Time `json:"at_ts,omitempty"` // момент из истории, по которому взята at_price
+	Missing       bool       `json:"missing"`         // цену на момент at по истории не восстановить
+	Change        *float64   `json:"change,omitempty"`
+	ChangePct     *float64   `json:"change_pct,omitempty"`
+}
+
+// GET /api/games/:id/snapshot-diff?at=<RFC3339> — текущие цены против цен на момент at.
+// Цена на момент at — new_price последнего изменения не позже at; если до at изменений не было,
+// то old_price первого изменения после. Без истории изменений исход помечается missing.
+func gameSnapshotDiffHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		at, err := queryTime(c, "at")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		if at == nil {
+			badRequest(c, errors.New("at is required"))
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT l.bookmaker, l.market_id, l.market_name, l.selection_id, l.selection_name, l.price_dec::float8,
+			       COALESCE(b.new_price, a.old_price)::float8, COALESCE(b.ts, a.ts)
+			FROM liveodds l
+			LEFT JOIN LATERAL (
+				SELECT new_price, ts FROM price_events e
+				WHERE e.game_id = l.game_id AND e.bookmaker = l.bookmaker
+				  AND e.market_id = l.market_id AND e.selection_id = l.selection_id AND e.ts <= $2
+				ORDER BY e.ts DESC, e.id DESC LIMIT 1
+			) b ON true
+			LEFT JOIN LATERAL (
+				SELECT old_price, ts FROM price_events e
+				WHERE e.game_id = l.game_id AND e.bookmaker = l.bookmaker
+				  AND e.market_id = l.market_id AND e.selection_id = l.selection_id AND e.ts > $2
+				ORDER BY e.ts, e.id LIMIT 1
+			) a ON true
+			WHERE l.game_id = $1
+			ORDER BY l.market_id, l.selection_id, l.bookmaker`, c.Param("id"), *at)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		defer rows.Close()
+
+		out := []SnapshotDiffView{}
+		for rows.Next() {
+			var d SnapshotDiffView
+			if err := rows.Scan(&d.Bookmaker, &d.MarketID, &d.MarketName, &d.SelectionID, &d.SelectionName,
+				&d.Latest, &d.AtPrice, &d.AtTS); err != nil {
+				serverError(c, err)
+				return
+			}
+			d.Missing = d.AtPrice == nil
+			if d.AtPrice != nil && d.Latest != nil && *d.AtPrice != 0 {
+				change := roundPrice(*d.Latest - *d.AtPrice)
+				pct := math.Round(change / *d.AtPrice * 10000) / 100
+				d.Change, d.ChangePct = &change, &pct
+			}
+			out = append(out, d)
+		}
+		if err := rows.Err(); err != nil {
+			serverError(c, err)
+			return
+		}
+
+		respondList(c, "selections", out, len(out), len(out), 0, gin.H{"game_id": c.Param("id"), "at": at.UTC()})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSmoothedWeightsPricesByHoldTime(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+	withRounding(t, 3, RoundHalfUp)
+
+	odd := LiveOdd{
+		GameID: "g1", Sport: "soccer", Bookmaker: "bet365", MarketID: "m1", MarketName: "Fulltime Result",
+		SelectionID: "s1", SelectionName: "Home", PriceDec: "3", PriceFrac: "2/1",
+	}
+	if err := insertLiveOdds(ctx, pool, []LiveOdd{odd}); err != nil {
+		t.Fatal(err)
+	}
+	// 2.0 держалась 90 секунд, 3.0 — последние 10: среднее по времени 2.1, а не 2.5
+	_, err := pool.Exec(ctx, `
+		INSERT INTO price_events (game_id, bookmaker, market_id, selection_id, old_price, new_price, delta, ts)
+		VALUES ('g1', 'bet365', 'm1', 's1', 1.5, 2.0, 0.5, now() - interval '100 seconds'),
+		       ('g1', 'bet365', 'm1', 's1', 2.0, 3.0, 1.0, now() - interval '10 seconds')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/games/:id/smoothed", gameSmoothedHandler(newDBHandle("DB", "", pool)))
+
+	for _, tt := range []struct {
+		window   string
+		min, max float64
+		samples  int
+	}{
+		{"2", 2.09, 2.12, 2}, // время теста немного удлиняет последний интервал
+		{"1", 3, 3, 1},
+	} {
+		var resp struct {
+			Smoothed []SmoothedView `json:"smoothed"`
+		}
+		getJSON(t, r, "/api/games/g1/smoothed?window="+tt.window, &resp)
+		if len(resp.Smoothed) != 1 || resp.Smoothed[0].Smoothed == nil {
+			t.Fatalf("window=%s: %+v, want one smoothed selection", tt.window, resp.Smoothed)
+		}
+		s := resp.Smoothed[0]
+		if *s.Smoothed < tt.min || *s.Smoothed > tt.max || s.Samples != tt.samples || *s.Latest != 3 {
+			t.Errorf("window=%s: smoothed=%v samples=%d latest=%v, want %v..%v, %d samples, latest 3",
+				tt.window, *s.Smoothed, s.Samples, *s.Latest, tt.min, tt.max, tt.samples)
+		}
+	}
+}
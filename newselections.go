@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// --- NEW SELECTIONS ---
+// first_seen — время первой вставки исхода в liveodds; ON CONFLICT его не трогает.
+// is_new в ответах API — исход появился не раньше NEW_SELECTION_WINDOW (5m) назад:
+// по нему UI рисует бейдж «новый рынок». Строки, вставленные до появления колонки,
+// имеют first_seen = NULL и новыми не считаются. 0 — флаг всегда false.
+
+var newSelectionWindow = 5 * time.Minute
+
+// isNewExpr — SQL-выражение is_new для выборок из liveodds (alias — префикс колонки, "" или "lo.").
+func isNewExpr(alias string) string {
+	return fmt.Sprintf("COALESCE(%sfirst_seen > now() - make_interval(secs => %d), false)",
+		alias, int64(newSelectionWindow/time.Second))
+}
@@ -0,0 +1,79 @@
+package main
+
+import "github.com/gin-gonic/gin"
+
+// --- ODDS FORMATS ---
+// ?format=hongkong,indonesian — дополнительные представления цены в ответах с коэффициентами
+// (price_hk, price_indo). По умолчанию только десятичная цена и дробь, как раньше.
+//
+//	Hong Kong:  d - 1                        (2.50 → 1.50, 1.80 → 0.80)
+//	Indonesian: d - 1 при d >= 2, иначе -1/(d-1)  (2.50 → 1.50, 1.80 → -1.25)
+//
+// Равные шансы (d = 2) — 1.00 в обоих форматах. Цена <= 1 или её отсутствие — null.
+
+const (
+	FormatHongKong   = "hongkong"
+	FormatIndonesian = "indonesian"
+)
+
+type oddsFormats struct {
+	hongKong, indonesian bool
+}
+
+func queryOddsFormats(c *gin.Context) (oddsFormats, error) {
+	list, err := queryList(c, "format", FormatHongKong, FormatIndonesian)
+	if err != nil {
+		return oddsFormats{}, err
+	}
+	var f oddsFormats
+	for _, v := range list {
+		switch v {
+		case FormatHongKong:
+			f.hongKong = true
+		case FormatIndonesian:
+			f.indonesian = true
+		}
+	}
+	return f, nil
+}
+
+// apply возвращает запрошенные представления цены; незапрошенные — nil (в JSON их не будет).
+func (f oddsFormats) apply(price *float64) (hk, indo *float64) {
+	if price == nil {
+		return nil, nil
+	}
+	if f.hongKong {
+		hk = decimalToHongKong(*price)
+	}
+	if f.indonesian {
+		indo = decimalToIndonesian(*price)
+	}
+	return hk, indo
+}
+
+func (f oddsFormats) applyList(odds []ListOddView) {
+	for i := range odds {
+		odds[i].PriceHK, odds[i].PriceIndo = f.apply(odds[i].Price)
+	}
+}
+
+func decimalToHongKong(d float64) *float64 {
+	if d <= 1 {
+		return nil
+	}
+	v := roundPrice(d - 1)
+	return &v
+}
+
+func decimalToIndonesian(d float64) *float64 {
+	if d <= 1 {
+		return nil
+	}
+	var v float64
+	if d >= 2 {
+		v = roundPrice(d - 1)
+	} else {
+		v = roundPrice(-1 / (d - 1))
+	}
+	return &v
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- ODDS QUERY ---
+
+// OddsRowView — строка liveodds для выгрузок: исход вместе с матчем и временем получения.
+type OddsRowView struct {
+	GameID string `json:"game_id"`
+	Sport  string `json:"sport"`
+	OddView
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// GET /api/odds — коэффициенты напрямую из liveodds, для выгрузок и скриптов.
+//
+//	game_id, sport, market_id, bookmaker — точные фильтры
+//	fetched_after=<RFC3339>             — только строки, полученные позже
+//	limit=100 (до 1000), offset=0       — пагинация, порядок стабильный
+//
+// Нужен хотя бы один фильтр — иначе это полный скан таблицы.
+func oddsQueryHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		fetchedAfter, err := queryTime(c, "fetched_after")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		limit, err := queryInt(c, "limit", 100, 1, 1000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		offset, err := queryInt(c, "offset", 0, 0, 1_000_000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		var where []string
+		var args []any
+		for _, col := range []string{"game_id", "sport", "market_id", "bookmaker"} {
+			if v := c.Query(col); v != "" {
+				args = append(args, v)
+				where = append(where, fmt.Sprintf("%s = $%d", col, len(args)))
+			}
+		}
+		if fetchedAfter != nil {
+			args = append(args, *fetchedAfter)
+			where = append(where, fmt.Sprintf("fetched_at > $%d", len(args)))
+		}
+		if len(where) == 0 {
+			badRequest(c, errors.New("at least one filter is required: game_id, sport, market_id, bookmaker or fetched_after"))
+			return
+		}
+
+		args = append(args, limit, offset)
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT game_id, sport, bookmaker, market_id, market_name, market_key, market_shape, selection_count,
+			       selection_id, selection_name, line, line_type, price_dec::text, price_dec::float8, price_frac,
+			       is_suspended, status, `+isNewExpr("")+`, fetched_at
+			FROM liveodds
+			WHERE `+strings.Join(where, " AND ")+`
+			ORDER BY game_id, bookmaker, market_id, selection_id
+			LIMIT `+fmt.Sprintf("$%d OFFSET $%d", len(args)-1, len(args)), args...)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		out, err := scanAll(rows, func(r rowScanner) (OddsRowView, error) {
+			var o OddsRowView
+			err := r.Scan(&o.GameID, &o.Sport, &o.Bookmaker, &o.MarketID, &o.MarketName, &o.MarketKey, &o.MarketShape, &o.SelectionCount,
+				&o.SelectionID, &o.SelectionName, &o.Line, &o.LineType, &o.PriceDec, &o.Price, &o.PriceFrac,
+				&o.IsSuspended, &o.Status, &o.IsNew, &o.FetchedAt)
+			return o, err
+		})
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		if out == nil {
+			out = []OddsRowView{}
+		}
+		respondList(c, "odds", out, len(out), limit, offset, nil)
+	}
+}
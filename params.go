@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- QUERY PARAMS ---
+// Хелперы разбора query-параметров: при некорректном значении возвращают ошибку,
+// которую хендлер отдаёт клиенту как 400 через badRequest.
+
+func badRequest(c *gin.Context, err error) {
+	c.JSON(400, gin.H{"error": err.Error()})
+}
+
+func queryInt(c *gin.Context, key string, def, min, max int) (int, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok || raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", key, raw)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%s must be between %d and %d, got %d", key, min, max, n)
+	}
+	return n, nil
+}
+
+func queryBool(c *gin.Context, key string, def bool) (bool, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok || raw == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", key, raw)
+	}
+	return b, nil
+}
+
+func queryEnum(c *gin.Context, key, def string, allowed ...string) (string, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok || raw == "" {
+		return def, nil
+	}
+	if !slices.Contains(allowed, raw) {
+		return "", fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), raw)
+	}
+	return raw, nil
+}
+
+// queryList разбирает список через запятую; каждое значение должно быть из allowed.
+func queryList(c *gin.Context, key string, allowed ...string) ([]string, error) {
+	var out []string
+	for _, v := range strings.Split(c.Query(key), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !slices.Contains(allowed, v) {
+			return nil, fmt.Errorf("%s: unknown value %q (allowed: %s)", key, v, strings.Join(allowed, ", "))
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// queryTime разбирает RFC3339-время; nil, если параметр не задан.
+func queryTime(c *gin.Context, key string) (*time.Time, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp, got %q", key, raw)
+	}
+	return &t, nil
+}
+
+// queryDay — границы календарного дня ?date=YYYY-MM-DD в поясе ?tz= (IANA, по умолчанию UTC):
+// [полночь, следующая полночь). ok=false, если date не задан. Конец дня — AddDate, а не +24h,
+// чтобы дни перевода часов были 23 и 25 часов.
+func queryDay(c *gin.Context, dateKey, tzKey string) (from, to time.Time, ok bool, err error) {
+	loc := time.UTC
+	if tz := c.Query(tzKey); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return from, to, false, fmt.Errorf("%s must be an IANA time zone, got %q", tzKey, tz)
+		}
+	}
+	raw := c.Query(dateKey)
+	if raw == "" {
+		return from, to, false, nil
+	}
+	from, err = time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		return from, to, false, fmt.Errorf("%s must be a YYYY-MM-DD date, got %q", dateKey, raw)
+	}
+	return from, from.AddDate(0, 0, 1), true, nil
+}
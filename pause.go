@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- PAUSE ---
+// Ручная остановка записи в БД без рестарта: POST /admin/pause и /admin/resume.
+// На паузе синки (HTTP и планировщик) и компакция ничего не запрашивают и не пишут.
+
+type ingestPause struct {
+	mu     sync.RWMutex
+	paused bool
+	since  time.Time
+}
+
+var pause = &ingestPause{}
+
+func (p *ingestPause) set(paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused != paused {
+		p.paused, p.since = paused, time.Now()
+	}
+}
+
+func (p *ingestPause) active() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.paused
+}
+
+func (p *ingestPause) stats() gin.H {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	resp := gin.H{"paused": p.paused}
+	if !p.since.IsZero() {
+		resp["since"] = p.since
+	}
+	return resp
+}
+
+// pauseGuard отвечает 503 "paused" на запуск синка, пока ingestion на паузе.
+func pauseGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if pause.active() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "paused"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func pauseHandler(paused bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pause.set(paused)
+		if paused {
+			logf(c.Request.Context(), "⏸️ Ingestion paused")
+		} else {
+			logf(c.Request.Context(), "▶️ Ingestion resumed")
+		}
+		c.JSON(http.StatusOK, pause.stats())
+	}
+}
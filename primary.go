@@ -0,0 +1,43 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// --- PRIMARY MARKET ---
+// Основной рынок матча по видам спорта: market_id или название рынка. Им пользуются
+// primary_odds_only в /api/games и /api/games/:id/compare и коэффициенты в /api/live,
+// чтобы «основные коэффициенты» везде значили одно и то же.
+// Переопределяется PRIMARY_MARKET_<SPORT>=Fulltime Result,40 (несколько значений через запятую).
+var defaultPrimaryMarkets = map[string][]string{
+	"soccer": {"Fulltime Result"},
+	"tennis": {"To Win Match"},
+}
+
+// primaryMarkets — значения в нижнем регистре; для неизвестного спорта пустой список (ни одного рынка).
+func primaryMarkets(sport string) []string {
+	markets := getEnvList("PRIMARY_MARKET_"+strings.ToUpper(sport), defaultPrimaryMarkets[sport])
+	out := make([]string, len(markets))
+	for i, m := range markets {
+		out[i] = strings.ToLower(m)
+	}
+	return out
+}
+
+// isPrimaryMarket — совпадает ли рынок с одним из primary (результат primaryMarkets) по ID или названию.
+func isPrimaryMarket(primary []string, marketID, marketName string) bool {
+	return slices.Contains(primary, strings.ToLower(marketID)) || slices.Contains(primary, strings.ToLower(marketName))
+}
+
+// primaryOdds — исходы основного рынка спорта из всех коэффициентов матча; всегда не nil.
+func primaryOdds(sport string, odds []ListOddView) []ListOddView {
+	primary := primaryMarkets(sport)
+	out := []ListOddView{}
+	for _, o := range odds {
+		if isPrimaryMarket(primary, o.MarketID, o.MarketName) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
@@ -0,0 +1,598 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: proto/games.proto
+
+package gamespb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GameList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Games         []*Game                `protobuf:"bytes,1,rep,name=games,proto3" json:"games,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	ServerTimeMs  int64                  `protobuf:"varint,5,opt,name=server_time_ms,json=serverTimeMs,proto3" json:"server_time_ms,omitempty"`
+	Removed       []string               `protobuf:"bytes,6,rep,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameList) Reset() {
+	*x = GameList{}
+	mi := &file_proto_games_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameList) ProtoMessage() {}
+
+func (x *GameList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_games_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameList.ProtoReflect.Descriptor instead.
+func (*GameList) Descriptor() ([]byte, []int) {
+	return file_proto_games_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GameList) GetGames() []*Game {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+func (x *GameList) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *GameList) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GameList) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GameList) GetServerTimeMs() int64 {
+	if x != nil {
+		return x.ServerTimeMs
+	}
+	return 0
+}
+
+func (x *GameList) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+type Game struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GameId            string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Sport             string                 `protobuf:"bytes,2,opt,name=sport,proto3" json:"sport,omitempty"`
+	League            string                 `protobuf:"bytes,3,opt,name=league,proto3" json:"league,omitempty"`
+	HomeTeam          string                 `protobuf:"bytes,4,opt,name=home_team,json=homeTeam,proto3" json:"home_team,omitempty"`
+	AwayTeam          string                 `protobuf:"bytes,5,opt,name=away_team,json=awayTeam,proto3" json:"away_team,omitempty"`
+	TimeStatus        string                 `protobuf:"bytes,6,opt,name=time_status,json=timeStatus,proto3" json:"time_status,omitempty"`
+	StartsAt          *int64                 `protobuf:"varint,7,opt,name=starts_at,json=startsAt,proto3,oneof" json:"starts_at,omitempty"`
+	StartsAtEstimated bool                   `protobuf:"varint,8,opt,name=starts_at_estimated,json=startsAtEstimated,proto3" json:"starts_at_estimated,omitempty"`
+	ScoresDetail      *ScoreBoard            `protobuf:"bytes,9,opt,name=scores_detail,json=scoresDetail,proto3" json:"scores_detail,omitempty"`
+	Odds              []*Odd                 `protobuf:"bytes,10,rep,name=odds,proto3" json:"odds,omitempty"`
+	OddsError         bool                   `protobuf:"varint,11,opt,name=odds_error,json=oddsError,proto3" json:"odds_error,omitempty"`
+	MarketCount       *int32                 `protobuf:"varint,12,opt,name=market_count,json=marketCount,proto3,oneof" json:"market_count,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Game) Reset() {
+	*x = Game{}
+	mi := &file_proto_games_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Game) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Game) ProtoMessage() {}
+
+func (x *Game) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_games_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Game.ProtoReflect.Descriptor instead.
+func (*Game) Descriptor() ([]byte, []int) {
+	return file_proto_games_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Game) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *Game) GetSport() string {
+	if x != nil {
+		return x.Sport
+	}
+	return ""
+}
+
+func (x *Game) GetLeague() string {
+	if x != nil {
+		return x.League
+	}
+	return ""
+}
+
+func (x *Game) GetHomeTeam() string {
+	if x != nil {
+		return x.HomeTeam
+	}
+	return ""
+}
+
+func (x *Game) GetAwayTeam() string {
+	if x != nil {
+		return x.AwayTeam
+	}
+	return ""
+}
+
+func (x *Game) GetTimeStatus() string {
+	if x != nil {
+		return x.TimeStatus
+	}
+	return ""
+}
+
+func (x *Game) GetStartsAt() int64 {
+	if x != nil && x.StartsAt != nil {
+		return *x.StartsAt
+	}
+	return 0
+}
+
+func (x *Game) GetStartsAtEstimated() bool {
+	if x != nil {
+		return x.StartsAtEstimated
+	}
+	return false
+}
+
+func (x *Game) GetScoresDetail() *ScoreBoard {
+	if x != nil {
+		return x.ScoresDetail
+	}
+	return nil
+}
+
+func (x *Game) GetOdds() []*Odd {
+	if x != nil {
+		return x.Odds
+	}
+	return nil
+}
+
+func (x *Game) GetOddsError() bool {
+	if x != nil {
+		return x.OddsError
+	}
+	return false
+}
+
+func (x *Game) GetMarketCount() int32 {
+	if x != nil && x.MarketCount != nil {
+		return *x.MarketCount
+	}
+	return 0
+}
+
+type ScoreBoard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Display       string                 `protobuf:"bytes,1,opt,name=display,proto3" json:"display,omitempty"`
+	Sets          []*SetScore            `protobuf:"bytes,2,rep,name=sets,proto3" json:"sets,omitempty"`
+	Points        string                 `protobuf:"bytes,3,opt,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreBoard) Reset() {
+	*x = ScoreBoard{}
+	mi := &file_proto_games_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreBoard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreBoard) ProtoMessage() {}
+
+func (x *ScoreBoard) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_games_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreBoard.ProtoReflect.Descriptor instead.
+func (*ScoreBoard) Descriptor() ([]byte, []int) {
+	return file_proto_games_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScoreBoard) GetDisplay() string {
+	if x != nil {
+		return x.Display
+	}
+	return ""
+}
+
+func (x *ScoreBoard) GetSets() []*SetScore {
+	if x != nil {
+		return x.Sets
+	}
+	return nil
+}
+
+func (x *ScoreBoard) GetPoints() string {
+	if x != nil {
+		return x.Points
+	}
+	return ""
+}
+
+type SetScore struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Set           int32                  `protobuf:"varint,1,opt,name=set,proto3" json:"set,omitempty"`
+	Home          string                 `protobuf:"bytes,2,opt,name=home,proto3" json:"home,omitempty"`
+	Away          string                 `protobuf:"bytes,3,opt,name=away,proto3" json:"away,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetScore) Reset() {
+	*x = SetScore{}
+	mi := &file_proto_games_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetScore) ProtoMessage() {}
+
+func (x *SetScore) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_games_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetScore.ProtoReflect.Descriptor instead.
+func (*SetScore) Descriptor() ([]byte, []int) {
+	return file_proto_games_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SetScore) GetSet() int32 {
+	if x != nil {
+		return x.Set
+	}
+	return 0
+}
+
+func (x *SetScore) GetHome() string {
+	if x != nil {
+		return x.Home
+	}
+	return ""
+}
+
+func (x *SetScore) GetAway() string {
+	if x != nil {
+		return x.Away
+	}
+	return ""
+}
+
+type Odd struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Bookmaker      string                 `protobuf:"bytes,1,opt,name=bookmaker,proto3" json:"bookmaker,omitempty"`
+	MarketId       string                 `protobuf:"bytes,2,opt,name=market_id,json=marketId,proto3" json:"market_id,omitempty"`
+	MarketName     string                 `protobuf:"bytes,3,opt,name=market_name,json=marketName,proto3" json:"market_name,omitempty"`
+	MarketShape    string                 `protobuf:"bytes,4,opt,name=market_shape,json=marketShape,proto3" json:"market_shape,omitempty"`
+	SelectionCount int32                  `protobuf:"varint,5,opt,name=selection_count,json=selectionCount,proto3" json:"selection_count,omitempty"`
+	SelectionName  string                 `protobuf:"bytes,6,opt,name=selection_name,json=selectionName,proto3" json:"selection_name,omitempty"`
+	PriceDec       *string                `protobuf:"bytes,7,opt,name=price_dec,json=priceDec,proto3,oneof" json:"price_dec,omitempty"`
+	Price          *float64               `protobuf:"fixed64,8,opt,name=price,proto3,oneof" json:"price,omitempty"`
+	IsSuspended    bool                   `protobuf:"varint,9,opt,name=is_suspended,json=isSuspended,proto3" json:"is_suspended,omitempty"`
+	Status         string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	IsNew          bool                   `protobuf:"varint,11,opt,name=is_new,json=isNew,proto3" json:"is_new,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Odd) Reset() {
+	*x = Odd{}
+	mi := &file_proto_games_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Odd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Odd) ProtoMessage() {}
+
+func (x *Odd) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_games_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Odd.ProtoReflect.Descriptor instead.
+func (*Odd) Descriptor() ([]byte, []int) {
+	return file_proto_games_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Odd) GetBookmaker() string {
+	if x != nil {
+		return x.Bookmaker
+	}
+	return ""
+}
+
+func (x *Odd) GetMarketId() string {
+	if x != nil {
+		return x.MarketId
+	}
+	return ""
+}
+
+func (x *Odd) GetMarketName() string {
+	if x != nil {
+		return x.MarketName
+	}
+	return ""
+}
+
+func (x *Odd) GetMarketShape() string {
+	if x != nil {
+		return x.MarketShape
+	}
+	return ""
+}
+
+func (x *Odd) GetSelectionCount() int32 {
+	if x != nil {
+		return x.SelectionCount
+	}
+	return 0
+}
+
+func (x *Odd) GetSelectionName() string {
+	if x != nil {
+		return x.SelectionName
+	}
+	return ""
+}
+
+func (x *Odd) GetPriceDec() string {
+	if x != nil && x.PriceDec != nil {
+		return *x.PriceDec
+	}
+	return ""
+}
+
+func (x *Odd) GetPrice() float64 {
+	if x != nil && x.Price != nil {
+		return *x.Price
+	}
+	return 0
+}
+
+func (x *Odd) GetIsSuspended() bool {
+	if x != nil {
+		return x.IsSuspended
+	}
+	return false
+}
+
+func (x *Odd) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Odd) GetIsNew() bool {
+	if x != nil {
+		return x.IsNew
+	}
+	return false
+}
+
+var File_proto_games_proto protoreflect.FileDescriptor
+
+const file_proto_games_proto_rawDesc = "" +
+	"\n" +
+	"\x11proto/games.proto\x12\vjonathan.v1\"\xb7\x01\n" +
+	"\bGameList\x12'\n" +
+	"\x05games\x18\x01 \x03(\v2\x11.jonathan.v1.GameR\x05games\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12$\n" +
+	"\x0eserver_time_ms\x18\x05 \x01(\x03R\fserverTimeMs\x12\x18\n" +
+	"\aremoved\x18\x06 \x03(\tR\aremoved\"\xc4\x03\n" +
+	"\x04Game\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x14\n" +
+	"\x05sport\x18\x02 \x01(\tR\x05sport\x12\x16\n" +
+	"\x06league\x18\x03 \x01(\tR\x06league\x12\x1b\n" +
+	"\thome_team\x18\x04 \x01(\tR\bhomeTeam\x12\x1b\n" +
+	"\taway_team\x18\x05 \x01(\tR\bawayTeam\x12\x1f\n" +
+	"\vtime_status\x18\x06 \x01(\tR\n" +
+	"timeStatus\x12 \n" +
+	"\tstarts_at\x18\a \x01(\x03H\x00R\bstartsAt\x88\x01\x01\x12.\n" +
+	"\x13starts_at_estimated\x18\b \x01(\bR\x11startsAtEstimated\x12<\n" +
+	"\rscores_detail\x18\t \x01(\v2\x17.jonathan.v1.ScoreBoardR\fscoresDetail\x12$\n" +
+	"\x04odds\x18\n" +
+	" \x03(\v2\x10.jonathan.v1.OddR\x04odds\x12\x1d\n" +
+	"\n" +
+	"odds_error\x18\v \x01(\bR\toddsError\x12&\n" +
+	"\fmarket_count\x18\f \x01(\x05H\x01R\vmarketCount\x88\x01\x01B\f\n" +
+	"\n" +
+	"_starts_atB\x0f\n" +
+	"\r_market_count\"i\n" +
+	"\n" +
+	"ScoreBoard\x12\x18\n" +
+	"\adisplay\x18\x01 \x01(\tR\adisplay\x12)\n" +
+	"\x04sets\x18\x02 \x03(\v2\x15.jonathan.v1.SetScoreR\x04sets\x12\x16\n" +
+	"\x06points\x18\x03 \x01(\tR\x06points\"D\n" +
+	"\bSetScore\x12\x10\n" +
+	"\x03set\x18\x01 \x01(\x05R\x03set\x12\x12\n" +
+	"\x04home\x18\x02 \x01(\tR\x04home\x12\x12\n" +
+	"\x04away\x18\x03 \x01(\tR\x04away\"\xfb\x02\n" +
+	"\x03Odd\x12\x1c\n" +
+	"\tbookmaker\x18\x01 \x01(\tR\tbookmaker\x12\x1b\n" +
+	"\tmarket_id\x18\x02 \x01(\tR\bmarketId\x12\x1f\n" +
+	"\vmarket_name\x18\x03 \x01(\tR\n" +
+	"marketName\x12!\n" +
+	"\fmarket_shape\x18\x04 \x01(\tR\vmarketShape\x12'\n" +
+	"\x0fselection_count\x18\x05 \x01(\x05R\x0eselectionCount\x12%\n" +
+	"\x0eselection_name\x18\x06 \x01(\tR\rselectionName\x12 \n" +
+	"\tprice_dec\x18\a \x01(\tH\x00R\bpriceDec\x88\x01\x01\x12\x19\n" +
+	"\x05price\x18\b \x01(\x01H\x01R\x05price\x88\x01\x01\x12!\n" +
+	"\fis_suspended\x18\t \x01(\bR\visSuspended\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12\x15\n" +
+	"\x06is_new\x18\v \x01(\bR\x05isNewB\f\n" +
+	"\n" +
+	"_price_decB\b\n" +
+	"\x06_priceB\x18Z\x16jonathan/proto;gamespbb\x06proto3"
+
+var (
+	file_proto_games_proto_rawDescOnce sync.Once
+	file_proto_games_proto_rawDescData []byte
+)
+
+func file_proto_games_proto_rawDescGZIP() []byte {
+	file_proto_games_proto_rawDescOnce.Do(func() {
+		file_proto_games_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_games_proto_rawDesc), len(file_proto_games_proto_rawDesc)))
+	})
+	return file_proto_games_proto_rawDescData
+}
+
+var file_proto_games_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_games_proto_goTypes = []any{
+	(*GameList)(nil),   // 0: jonathan.v1.GameList
+	(*Game)(nil),       // 1: jonathan.v1.Game
+	(*ScoreBoard)(nil), // 2: jonathan.v1.ScoreBoard
+	(*SetScore)(nil),   // 3: jonathan.v1.SetScore
+	(*Odd)(nil),        // 4: jonathan.v1.Odd
+}
+var file_proto_games_proto_depIdxs = []int32{
+	1, // 0: jonathan.v1.GameList.games:type_name -> jonathan.v1.Game
+	2, // 1: jonathan.v1.Game.scores_detail:type_name -> jonathan.v1.ScoreBoard
+	4, // 2: jonathan.v1.Game.odds:type_name -> jonathan.v1.Odd
+	3, // 3: jonathan.v1.ScoreBoard.sets:type_name -> jonathan.v1.SetScore
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_proto_games_proto_init() }
+func file_proto_games_proto_init() {
+	if File_proto_games_proto != nil {
+		return
+	}
+	file_proto_games_proto_msgTypes[1].OneofWrappers = []any{}
+	file_proto_games_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_games_proto_rawDesc), len(file_proto_games_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_games_proto_goTypes,
+		DependencyIndexes: file_proto_games_proto_depIdxs,
+		MessageInfos:      file_proto_games_proto_msgTypes,
+	}.Build()
+	File_proto_games_proto = out.File
+	file_proto_games_proto_goTypes = nil
+	file_proto_games_proto_depIdxs = nil
+}
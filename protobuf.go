@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+
+	gamespb "jonathan/proto"
+)
+
+// --- PROTOBUF ---
+// GET /api/games с Accept: application/x-protobuf отдаёт GameList из proto/games.proto.
+// Типы сгенерированы protoc-gen-go в proto/games.pb.go (команда — в шапке games.proto);
+// здесь только перекладка GameView/ListOddView в них.
+
+const protobufContentType = "application/x-protobuf"
+
+func wantsProtobuf(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+	return strings.Contains(accept, protobufContentType) || strings.Contains(accept, "application/protobuf")
+}
+
+// gameListItem — матч из /api/games в типизированном виде для protobuf.
+type gameListItem struct {
+	GameView
+	Odds        []ListOddView
+	OddsError   bool
+	MarketCount *int // nil — не запрашивалось (include=market_count)
+}
+
+func respondProtobuf(c *gin.Context, games []gameListItem, removed []string, limit, offset int, serverTime time.Time) {
+	b, err := proto.Marshal(gameListProto(games, removed, limit, offset, serverTime))
+	if err != nil {
+		serverError(c, err)
+		return
+	}
+	c.Writer.Header().Add("Vary", "Accept")
+	c.Data(200, protobufContentType, b)
+}
+
+func gameListProto(games []gameListItem, removed []string, limit, offset int, serverTime time.Time) *gamespb.GameList {
+	list := &gamespb.GameList{
+		Count:        int32(len(games)),
+		Limit:        int32(limit),
+		Offset:       int32(offset),
+		ServerTimeMs: serverTime.UnixMilli(),
+		Removed:      removed,
+	}
+	for _, g := range games {
+		list.Games = append(list.Games, gameProto(g))
+	}
+	return list
+}
+
+func gameProto(g gameListItem) *gamespb.Game {
+	pb := &gamespb.Game{
+		GameId:            g.GameID,
+		Sport:             g.Sport,
+		League:            g.League,
+		HomeTeam:          g.Home,
+		AwayTeam:          g.Away,
+		TimeStatus:        g.Time,
+		StartsAtEstimated: g.StartsAtEstimated,
+		OddsError:         g.OddsError,
+	}
+	if g.StartsAt != nil {
+		pb.StartsAt = proto.Int64(g.StartsAt.Unix())
+	}
+	if sb := g.ScoresDetail; sb != nil {
+		pb.ScoresDetail = &gamespb.ScoreBoard{Display: sb.Display, Points: sb.Points}
+		for _, set := range sb.Sets {
+			pb.ScoresDetail.Sets = append(pb.ScoresDetail.Sets, &gamespb.SetScore{Set: int32(set.Set), Home: set.Home, Away: set.Away})
+		}
+	}
+	for _, o := range g.Odds {
+		pb.Odds = append(pb.Odds, oddProto(o))
+	}
+	if g.MarketCount != nil {
+		pb.MarketCount = proto.Int32(int32(*g.MarketCount))
+	}
+	return pb
+}
+
+func oddProto(o ListOddView) *gamespb.Odd {
+	return &gamespb.Odd{
+		Bookmaker:      o.Bookmaker,
+		MarketId:       o.MarketID,
+		MarketName:     o.MarketName,
+		MarketShape:    o.MarketShape,
+		SelectionCount: int32(o.SelectionCount),
+		SelectionName:  o.SelectionName,
+		PriceDec:       o.PriceDec,
+		Price:          o.Price,
+		IsSuspended:    o.IsSuspended,
+		Status:         o.Status,
+		IsNew:          o.IsNew,
+	}
+}
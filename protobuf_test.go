@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+
+	gamespb "jonathan/proto"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	starts := time.Date(2024, 6, 15, 19, 0, 0, 0, time.UTC)
+	serverTime := time.Date(2024, 6, 15, 18, 30, 0, 123e6, time.UTC)
+	priceDec, price, markets := "1.909", 1.909, 7
+
+	games := []gameListItem{
+		{
+			GameView: GameView{
+				GameID: "151234567890", Sport: "tennis", League: "ATP Halle", Home: "Nadal", Away: "Djokovic",
+				Time: "1", StartsAt: &starts, StartsAtEstimated: true,
+				ScoresDetail: &ScoreBoard{Display: "6-4 3-2", Points: "30-15", Sets: []SetScore{{1, "6", "4"}, {2, "3", "2"}}},
+			},
+			Odds: []ListOddView{
+				{Bookmaker: "bet365", MarketID: "13", MarketName: "To Win Match", MarketShape: "h2h", SelectionCount: 2,
+					SelectionName: "Nadal", PriceDec: &priceDec, Price: &price, Status: "open", IsNew: true},
+				{Bookmaker: "bet365", MarketID: "13", MarketName: "To Win Match", MarketShape: "h2h", SelectionCount: 2,
+					SelectionName: "Djokovic", IsSuspended: true, Status: "suspended"},
+			},
+			MarketCount: &markets,
+		},
+		{GameView: GameView{GameID: "2", Sport: "soccer", Time: "0"}, OddsError: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/games", nil)
+	respondProtobuf(c, games, []string{"gone1", "gone2"}, 50, 100, serverTime)
+
+	if ct := rec.Header().Get("Content-Type"); ct != protobufContentType {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	var got gamespb.GameList
+	if err := proto.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal with generated code: %v", err)
+	}
+
+	want := &gamespb.GameList{
+		Count: 2, Limit: 50, Offset: 100, ServerTimeMs: serverTime.UnixMilli(), Removed: []string{"gone1", "gone2"},
+		Games: []*gamespb.Game{
+			{
+				GameId: "151234567890", Sport: "tennis", League: "ATP Halle", HomeTeam: "Nadal", AwayTeam: "Djokovic",
+				TimeStatus: "1", StartsAt: proto.Int64(starts.Unix()), StartsAtEstimated: true,
+				ScoresDetail: &gamespb.ScoreBoard{Display: "6-4 3-2", Points: "30-15", Sets: []*gamespb.SetScore{
+					{Set: 1, Home: "6", Away: "4"}, {Set: 2, Home: "3", Away: "2"},
+				}},
+				Odds: []*gamespb.Odd{
+					{Bookmaker: "bet365", MarketId: "13", MarketName: "To Win Match", MarketShape: "h2h", SelectionCount: 2,
+						SelectionName: "Nadal", PriceDec: proto.String("1.909"), Price: proto.Float64(1.909), Status: "open", IsNew: true},
+					{Bookmaker: "bet365", MarketId: "13", MarketName: "To Win Match", MarketShape: "h2h", SelectionCount: 2,
+						SelectionName: "Djokovic", IsSuspended: true, Status: "suspended"},
+				},
+				MarketCount: proto.Int32(7),
+			},
+			{GameId: "2", Sport: "soccer", TimeStatus: "0", OddsError: true},
+		},
+	}
+	if !proto.Equal(&got, want) {
+		t.Fatalf("round trip:\n got  %v\n want %v", &got, want)
+	}
+	// optional без значения остаются неустановленными, а не нулём
+	if g := got.Games[1]; g.StartsAt != nil || g.MarketCount != nil {
+		t.Errorf("game 2: starts_at=%v market_count=%v, want unset", g.StartsAt, g.MarketCount)
+	}
+	if o := got.Games[0].Odds[1]; o.PriceDec != nil || o.Price != nil {
+		t.Errorf("odd without price: price_dec=%v price=%v, want unset", o.PriceDec, o.Price)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- REPARSE ---
+// POST /admin/reparse/:game_id — пересчитать производные колонки liveodds из сохранённого raw
+// без запроса к апстриму: после исправления разбора цены/названий/линии/SU фикс применяется к уже
+// записанным исходам. raw хранит только элемент PA, поэтому рынок (market_id/market_name) берётся
+// из строки как есть; market_key и форма рынка пересчитываются по нему.
+// Строки без raw (STORE_RAW=false) не меняются. price_events не пишутся: цена на рынке не двигалась.
+
+type reparseRow struct {
+	odd       LiveOdd
+	hasRaw    bool
+	parseFail bool
+}
+
+func reparseHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		ctx := c.Request.Context()
+		gameID := c.Param("game_id")
+
+		rows, err := loadReparseRows(ctx, db, gameID)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		if len(rows) == 0 {
+			c.JSON(404, gin.H{"error": "no odds stored for game"})
+			return
+		}
+
+		// форма рынка считается по всем исходам рынка у букмекера, в том числе без raw
+		byBookmaker := map[string][]LiveOdd{}
+		for _, r := range rows {
+			byBookmaker[r.odd.Bookmaker] = append(byBookmaker[r.odd.Bookmaker], r.odd)
+		}
+		shapes := map[[2]string]LiveOdd{}
+		for _, odds := range byBookmaker {
+			setMarketShapes(odds)
+			for _, o := range odds {
+				shapes[[2]string{o.Bookmaker, o.MarketID}] = o
+			}
+		}
+
+		batch := &pgx.Batch{}
+		reparsed, skipped, failed := 0, 0, 0
+		for _, r := range rows {
+			switch {
+			case !r.hasRaw:
+				skipped++
+				continue
+			case r.parseFail:
+				failed++
+				continue
+			}
+			o, shape := r.odd, shapes[[2]string{r.odd.Bookmaker, r.odd.MarketID}]
+			reparsed++
+			batch.Queue(`
+				UPDATE liveodds SET
+					selection_name=$5, line=$6, price_dec=NULLIF($7, '')::numeric, price_frac=$8,
+					market_key=$9, selection_count=$10, market_shape=$11, is_suspended=$12,
+					status = CASE WHEN status = 'closed' THEN status ELSE $13 END, line_type=$14
+				WHERE game_id=$1 AND bookmaker=$2 AND market_id=$3 AND selection_id=$4`,
+				gameID, o.Bookmaker, o.MarketID, o.SelectionID,
+				o.SelectionName, o.Line, o.PriceDec, o.PriceFrac,
+				marketKey(o.MarketName), shape.SelectionCount, shape.MarketShape, o.IsSuspended, marketStatus(o.IsSuspended), o.LineType)
+		}
+		if err := withTx(ctx, db, func(tx pgx.Tx) error { return execBatch(ctx, tx, batch) }); err != nil {
+			serverError(c, err)
+			return
+		}
+		logf(ctx, "♻️ Reparsed %d odds for game %s (%d without raw, %d unparseable)", reparsed, gameID, skipped, failed)
+		c.JSON(200, gin.H{"game_id": gameID, "reparsed": reparsed, "skipped_no_raw": skipped, "failed": failed})
+	}
+}
+
+// loadReparseRows читает исходы матча и разбирает их raw через parseSelection.
+func loadReparseRows(ctx context.Context, db *pgxpool.Pool, gameID string) ([]reparseRow, error) {
+	rows, err := db.Query(ctx, `
+		SELECT sport, bookmaker, market_id, market_name, selection_id, raw
+		FROM liveodds WHERE game_id = $1
+		ORDER BY bookmaker, market_id, selection_id`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAll(rows, func(r rowScanner) (reparseRow, error) {
+		var sport, bookmaker, marketID, marketName, selectionID, raw string
+		if err := r.Scan(&sport, &bookmaker, &marketID, &marketName, &selectionID, &raw); err != nil {
+			return reparseRow{}, err
+		}
+		row := reparseRow{odd: LiveOdd{
+			GameID: gameID, Sport: sport, Bookmaker: bookmaker,
+			MarketID: marketID, MarketName: marketName, SelectionID: selectionID,
+		}}
+		if raw == "" {
+			return row, nil
+		}
+		row.hasRaw = true
+		var item map[string]any
+		if err := decodeJSON([]byte(raw), &item); err != nil {
+			row.parseFail = true
+			return row, nil
+		}
+		o, ok := parseSelection(item, sport, marketName)
+		if !ok {
+			row.parseFail = true
+			return row, nil
+		}
+		o.GameID, o.Bookmaker, o.MarketID, o.MarketName = gameID, bookmaker, marketID, marketName
+		setLine(&o)
+		o.SelectionID = selectionID // ключ строки — сохранённый selection_id
+		row.odd = o
+		return row, nil
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- RESPONSE ENVELOPE ---
+// v1 (по умолчанию, как было): {"games": [...], ...}
+// v2: {"data": [...], "meta": {"count", "limit", "offset", "generated_at", ...}}
+// v2 включается заголовком X-API-Version: 2 или ?api_version=2;
+// API_VERSION=2 делает его умолчанием, когда все клиенты переедут.
+
+func wantsEnvelope(c *gin.Context) bool {
+	v := c.GetHeader("X-API-Version")
+	if q := c.Query("api_version"); q != "" {
+		v = q
+	}
+	if v == "" {
+		v = getEnv("API_VERSION", "1")
+	}
+	return v == "2"
+}
+
+// respondList отдаёт список в формате, который запросил клиент.
+// extra — дополнительные поля ответа: в v1 остаются на верхнем уровне, в v2 уходят в meta.
+func respondList(c *gin.Context, legacyKey string, data any, count, limit, offset int, extra gin.H) {
+	if !wantsEnvelope(c) {
+		resp := gin.H{legacyKey: data}
+		for k, v := range extra {
+			resp[k] = v
+		}
+		c.JSON(200, resp)
+		return
+	}
+
+	meta := gin.H{}
+	for k, v := range extra {
+		meta[k] = v
+	}
+	meta["count"] = count
+	meta["limit"] = limit
+	meta["offset"] = offset
+	meta["generated_at"] = time.Now().UTC()
+	c.JSON(200, gin.H{"data": data, "meta": meta})
+}
+
+// --- ETAG ---
+
+// weakETag — W/"..." от параметров запроса, формата ответа и данных.
+// Одинаковые данные в другом формате (v1/v2, JSON/protobuf) — другой ETag.
+func weakETag(c *gin.Context, data any) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write([]byte(c.GetHeader("Accept")))
+	if wantsEnvelope(c) {
+		h.Write([]byte("v2"))
+	}
+	h.Write([]byte{0})
+	json.NewEncoder(h).Encode(data)
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// notModified ставит ETag и отвечает 304, если клиент прислал совпадающий If-None-Match.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	c.Writer.Header().Add("Vary", "Accept, X-API-Version") // Add: gzip уже выставил Vary: Accept-Encoding
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		// сравнение слабое: W/ у клиента и у нас не учитывается
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(304)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+)
+
+// --- ROOT KEYS ---
+// Апстрим в разных версиях API отдаёт массив матчей под разными ключами: games / games_pre,
+// games_live, results, data. Ключи перебираются по порядку, берётся первый присутствующий.
+// Какой ключ сработал, логируется при первом прогоне и при каждой смене, чтобы переименование
+// на стороне апстрима было видно в логах, а не выглядело как пустая линия.
+
+var gamesRootKeys = map[string][]string{
+	"pre":  {"games_pre", "games", "results", "data"},
+	"live": {"games", "games_live", "results", "data"},
+}
+
+// lastRootKey — task/sport -> ключ, под которым пришли матчи в прошлый раз ("" — ни под каким).
+var lastRootKey sync.Map
+
+// gamesRoot достаёт массив матчей из ответа task=pre/live. nil без ошибки — ни одного
+// известного ключа нет (такое бывает, когда у вида спорта нет матчей).
+func gamesRoot(body []byte, task, sport string) (json.RawMessage, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(body, &root); err != nil {
+		return nil, err
+	}
+	for _, key := range gamesRootKeys[task] {
+		raw, ok := root[key]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		if prev, loaded := lastRootKey.Swap(task+"/"+sport, key); !loaded || prev != key {
+			log.Printf("🔑 %s/%s: games under root key %q", task, sport, key)
+		}
+		return raw, nil
+	}
+
+	// "" — в прошлый раз ключа тоже не было, повторно не шумим
+	if prev, loaded := lastRootKey.Swap(task+"/"+sport, ""); !loaded || prev != "" {
+		keys := make([]string, 0, len(root))
+		for k := range root {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		log.Printf("⚠️ %s/%s: none of root keys %v in response, got %v", task, sport, gamesRootKeys[task], keys)
+	}
+	return nil, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestGamesRootAlternateKeys(t *testing.T) {
+	tests := []struct {
+		task, body string
+		want       int
+	}{
+		{"live", `{"games":[{"game_id":"1"},{"game_id":"2"}]}`, 2},
+		{"live", `{"games_live":[{"game_id":"1"}]}`, 1},
+		{"live", `{"results":[{"game_id":"1"}]}`, 1},
+		{"live", `{"data":[{"game_id":"1"}]}`, 1},
+		{"live", `{"games":null,"data":[{"game_id":"1"}]}`, 1}, // null-ключ пропускается
+		{"pre", `{"games_pre":[{"game_id":"1"}],"games":[]}`, 1},
+		{"pre", `{"games":[{"game_id":"1"},{"game_id":"2"}]}`, 2},
+		{"pre", `{"success":1}`, 0},
+	}
+	for _, tt := range tests {
+		var games []Game
+		var err error
+		if tt.task == "pre" {
+			games, err = parsePreGames([]byte(tt.body), "soccer")
+		} else {
+			games, err = parseLiveGames([]byte(tt.body), "soccer")
+		}
+		if err != nil || len(games) != tt.want {
+			t.Errorf("%s %s: %d games, %v; want %d", tt.task, tt.body, len(games), err, tt.want)
+		}
+	}
+}
+
+func TestGamesRootKeyPriority(t *testing.T) {
+	raw, err := gamesRoot([]byte(`{"data":[3],"results":[2],"games":[1]}`), "live", "test")
+	if err != nil || string(raw) != "[1]" {
+		t.Fatalf("gamesRoot = %s, %v; want the first candidate key (games)", raw, err)
+	}
+	if _, err := gamesRoot([]byte(`not json`), "live", "test"); err == nil {
+		t.Fatal("gamesRoot on invalid JSON: want error")
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import "github.com/jackc/pgx/v5"
+
+// --- ROW SCANNING ---
+
+// rowScanner — то, что нужно функции разбора одной строки (pgx.Rows, pgx.Row).
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanAll читает все строки через scan и закрывает rows. Ошибка разбора любой строки
+// или rows.Err() возвращается, а не глотается — частичный список не отдаём.
+func scanAll[T any](rows pgx.Rows, scan func(rowScanner) (T, error)) ([]T, error) {
+	defer rows.Close()
+	var out []T
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func scanString(r rowScanner) (string, error) {
+	var s string
+	err := r.Scan(&s)
+	return s, err
+}
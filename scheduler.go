@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- SCHEDULER ---
+// Встроенный планировщик вместо внешнего cron, дергающего /sync-games и /update-liveodds.
+// Включается SCHEDULER_ENABLED=true. Интервалы — длительности Go ("90s", "5m") или секунды:
+//
+//	GAMES_INTERVAL=5m          — синк матчей
+//	ODDS_INTERVAL=60s          — коэффициенты по умолчанию
+//	ODDS_INTERVAL_SOCCER=15s   — отдельный тикер для вида спорта
+//	SCHEDULER_JITTER=5s        — случайная задержка старта и каждого прогона (0 — без джиттера)
+//
+// Джиттер разносит прогоны нескольких инстансов, чтобы они не били в апстрим в одну секунду.
+// Тикер остаётся ровным, задержка откладывает только сам прогон внутри тика, поэтому интервалы
+// не уплывают; джиттер ограничен половиной интервала, чтобы прогон не съезжал на следующий тик.
+// На паузе (/admin/pause) и во время окон обслуживания (MAINTENANCE_WINDOWS) прогоны пропускаются.
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	val := strings.TrimSpace(getEnv(key, ""))
+	if val == "" {
+		return fallback
+	}
+	if n, err := strconv.Atoi(val); err == nil {
+		return time.Duration(n) * time.Second
+	}
+	if d, err := time.ParseDuration(val); err == nil {
+		return d
+	}
+	log.Printf("⚠️ Invalid %s=%q, using %s", key, val, fallback)
+	return fallback
+}
+
+// oddsInterval — интервал обновления коэффициентов для вида спорта.
+func oddsInterval(sport string) time.Duration {
+	def := getEnvDuration("ODDS_INTERVAL", time.Minute)
+	return getEnvDuration("ODDS_INTERVAL_"+strings.ToUpper(sport), def)
+}
+
+func startScheduler(ctx context.Context, h *dbHandle) {
+	if !getEnvBool("SCHEDULER_ENABLED", false) {
+		return
+	}
+	gamesEvery := getEnvDuration("GAMES_INTERVAL", 5*time.Minute)
+	go runEvery(ctx, "games", gamesEvery, func(ctx context.Context) {
+		if _, err := syncGames(ctx, h.Pool(), sports, gameSources); err != nil {
+			logf(ctx, "❌ Scheduled games sync error: %v", err)
+		}
+	})
+	for _, sport := range sports {
+		every := oddsInterval(sport)
+		go runEvery(ctx, "odds/"+sport, every, func(ctx context.Context) {
+			if _, _, err := updateLiveOdds(ctx, h.Pool(), sport); err != nil {
+				logf(ctx, "❌ Scheduled %s odds update error: %v", sport, err)
+			}
+		})
+	}
+	log.Printf("⏱️ Scheduler started: games every %s, odds %s", gamesEvery, describeOddsIntervals())
+}
+
+func describeOddsIntervals() string {
+	parts := make([]string, len(sports))
+	for i, sport := range sports {
+		parts[i] = sport + "=" + oddsInterval(sport).String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// runEvery запускает job по тикеру. Если предыдущий прогон ещё идёт, тик пропускается,
+// чтобы медленный апстрим не копил очередь параллельных прогонов.
+func runEvery(ctx context.Context, name string, every time.Duration, job func(ctx context.Context)) {
+	if every <= 0 {
+		log.Printf("⚠️ Scheduler job %s disabled: interval %s", name, every)
+		return
+	}
+	jitter := min(getEnvDuration("SCHEDULER_JITTER", 5*time.Second), every/2)
+	if !sleepJitter(ctx, jitter) {
+		return
+	}
+
+	var running sync.Mutex
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for n := 1; ; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !sleepJitter(ctx, jitter) {
+			return
+		}
+		if pause.active() {
+			continue
+		}
+		if w, ok := inMaintenance(time.Now()); ok {
+			log.Printf("🛠️ Scheduler job %s skipped: maintenance window %s", name, w)
+			continue
+		}
+		if !running.TryLock() {
+			log.Printf("⏳ Scheduler job %s still running, tick skipped", name)
+			continue
+		}
+		// без отмены: по сигналу тикер остановится, а начатый прогон доработает (см. shutdown.go)
+		runCtx := context.WithValue(context.WithoutCancel(ctx), requestIDKey{}, name+"#"+strconv.Itoa(n))
+		go func() {
+			defer running.Unlock()
+			job(runCtx)
+		}()
+	}
+}
+
+// sleepJitter ждёт случайное время в [0, limit); false — контекст отменён.
+func sleepJitter(ctx context.Context, limit time.Duration) bool {
+	if limit <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(rand.N(limit))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- SCHEMA ---
+
+// Миграции выполняются при старте по порядку, каждая должна быть идемпотентной.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS games (
+		game_id     TEXT PRIMARY KEY,
+		sport       TEXT NOT NULL DEFAULT '',
+		bookmaker   TEXT NOT NULL DEFAULT '',
+		source      TEXT NOT NULL DEFAULT '',
+		league      TEXT NOT NULL DEFAULT '',
+		home_team   TEXT NOT NULL DEFAULT '',
+		away_team   TEXT NOT NULL DEFAULT '',
+		scores      TEXT NOT NULL DEFAULT '',
+		time_status TEXT NOT NULL DEFAULT '',
+		starts_at   TIMESTAMPTZ,
+		updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS liveodds (
+		game_id        TEXT NOT NULL,
+		sport          TEXT NOT NULL DEFAULT '',
+		bookmaker      TEXT NOT NULL DEFAULT '',
+		market_id      TEXT NOT NULL DEFAULT '',
+		market_name    TEXT NOT NULL DEFAULT '',
+		selection_id   TEXT NOT NULL DEFAULT '',
+		selection_name TEXT NOT NULL DEFAULT '',
+		line           TEXT NOT NULL DEFAULT '',
+		price_dec      TEXT NOT NULL DEFAULT '',
+		price_frac     TEXT NOT NULL DEFAULT '',
+		fetched_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+		raw            TEXT NOT NULL DEFAULT '',
+		UNIQUE (game_id, market_id, selection_id)
+	)`,
+	// Форма рынка: сколько исходов в группе MG (2 = h2h, 3 = 1x2)
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS selection_count INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS market_shape TEXT NOT NULL DEFAULT ''`,
+	// Канонический ключ рынка: 1x2 / totals / btts / other
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS market_key TEXT NOT NULL DEFAULT 'other'`,
+	// Несколько букмекеров на один матч: bookmaker входит в ключ уникальности
+	`ALTER TABLE liveodds DROP CONSTRAINT IF EXISTS liveodds_game_id_market_id_selection_id_key`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS liveodds_game_bookmaker_market_selection_key
+		ON liveodds (game_id, bookmaker, market_id, selection_id)`,
+	// Счёт по сетам для тенниса
+	`ALTER TABLE games ADD COLUMN IF NOT EXISTS scores_detail JSONB`,
+	// Цена открытия: пишется только при первой вставке исхода, ON CONFLICT её не трогает
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS opening_price_dec TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS is_suspended BOOLEAN NOT NULL DEFAULT false`,
+	// Журнал изменений цен: строка пишется, только когда цена исхода реально поменялась
+	`CREATE TABLE IF NOT EXISTS price_events (
+		id             BIGSERIAL PRIMARY KEY,
+		game_id        TEXT NOT NULL,
+		bookmaker      TEXT NOT NULL,
+		market_id      TEXT NOT NULL,
+		selection_id   TEXT NOT NULL,
+		selection_name TEXT NOT NULL DEFAULT '',
+		old_price      NUMERIC NOT NULL,
+		new_price      NUMERIC NOT NULL,
+		delta          NUMERIC NOT NULL,
+		ts             TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS price_events_game_ts_idx ON price_events (game_id, ts DESC)`,
+	// Десятичные цены — NUMERIC, NULL если пересчёт не удался (исходная дробь остаётся в price_frac)
+	numericPriceColumn("price_dec"),
+	numericPriceColumn("opening_price_dec"),
+	// starts_at проставлен сервером (live без времени начала), а не пришёл из фида
+	`ALTER TABLE games ADD COLUMN IF NOT EXISTS starts_at_estimated BOOLEAN NOT NULL DEFAULT false`,
+	// События матча (голы, карточки) из элементов EV ответа liveodds.
+	// Отдельно от price_events: там изменения цен, здесь — что происходит на поле.
+	`CREATE TABLE IF NOT EXISTS incidents (
+		id          BIGSERIAL PRIMARY KEY,
+		game_id     TEXT NOT NULL,
+		incident_id TEXT NOT NULL,
+		type        TEXT NOT NULL DEFAULT 'other',
+		minute      INT,
+		description TEXT NOT NULL DEFAULT '',
+		fetched_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (game_id, incident_id)
+	)`,
+	// Статус рынка: open / suspended / closed (см. marketstatus.go)
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'open'`,
+	// Сколько синков подряд активный матч не приходил в фиде (см. markMissingGames)
+	`ALTER TABLE games ADD COLUMN IF NOT EXISTS missed_syncs INT NOT NULL DEFAULT 0`,
+	// Что означает line: handicap / total / other / none (см. lineType); '' — ещё не пересчитано
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS line_type TEXT NOT NULL DEFAULT ''`,
+	// Когда исход появился впервые (см. newselections.go); у старых строк NULL — они не «новые»
+	`ALTER TABLE liveodds ADD COLUMN IF NOT EXISTS first_seen TIMESTAMPTZ`,
+	// Журнал прогонов синка (см. syncruns.go)
+	`CREATE TABLE IF NOT EXISTS sync_runs (
+		id          BIGSERIAL PRIMARY KEY,
+		task        TEXT NOT NULL,
+		sport       TEXT NOT NULL DEFAULT '',
+		source      TEXT NOT NULL DEFAULT '',
+		run_id      TEXT NOT NULL DEFAULT '',
+		started_at  TIMESTAMPTZ NOT NULL,
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		count       INT NOT NULL DEFAULT 0,
+		warnings    INT NOT NULL DEFAULT 0,
+		error       TEXT,
+		error_kind  TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS sync_runs_started_idx ON sync_runs (started_at DESC)`,
+	// Удалённые матчи для ?since= в /api/games: клиент узнаёт, что матча больше нет
+	`CREATE TABLE IF NOT EXISTS deleted_games (
+		game_id    TEXT PRIMARY KEY,
+		deleted_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS games_updated_at_idx ON games (updated_at)`,
+}
+
+// numericPriceColumn переводит текстовую колонку цены liveodds в NUMERIC NULL.
+// Пустые и нечисловые значения становятся NULL; на уже сконвертированной колонке ничего не делает.
+func numericPriceColumn(col string) string {
+	return fmt.Sprintf(`DO $$
+	BEGIN
+		IF (SELECT data_type FROM information_schema.columns
+		    WHERE table_name = 'liveodds' AND column_name = '%[1]s') = 'text' THEN
+			ALTER TABLE liveodds
+				ALTER COLUMN %[1]s DROP DEFAULT,
+				ALTER COLUMN %[1]s DROP NOT NULL,
+				ALTER COLUMN %[1]s TYPE NUMERIC
+					USING CASE WHEN %[1]s ~ '^[0-9]+(\.[0-9]+)?$' THEN %[1]s::numeric END;
+		END IF;
+	END $$`, col)
+}
+
+func migrate(pool *pgxpool.Pool) error {
+	for i, stmt := range migrations {
+		if _, err := pool.Exec(context.Background(), stmt); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- SCORES ---
+
+// ScoreBoard — структурированный счёт теннисного матча (хранится в games.scores_detail).
+type ScoreBoard struct {
+	Display string     `json:"display"`
+	Sets    []SetScore `json:"sets"`
+	Points  string     `json:"points,omitempty"`
+}
+
+type SetScore struct {
+	Set  int    `json:"set"`
+	Home string `json:"home"`
+	Away string `json:"away"`
+}
+
+// parseTennisScores собирает счёт по сетам из live-объекта апстрима.
+// Поддерживаются оба формата: "ss": "6-4,3-2" и "scores": {"1": {"home": "6", "away": "4"}, ...}.
+// Текущие очки в гейме берутся из "points".
+func parseTennisScores(m map[string]any) *ScoreBoard {
+	sb := &ScoreBoard{}
+
+	if ss, ok := m["ss"].(string); ok && strings.TrimSpace(ss) != "" {
+		for i, set := range strings.Split(ss, ",") {
+			home, away, _ := strings.Cut(strings.TrimSpace(set), "-")
+			sb.Sets = append(sb.Sets, SetScore{Set: i + 1, Home: home, Away: away})
+		}
+	} else if byset, ok := m["scores"].(map[string]any); ok {
+		for key, v := range byset {
+			n, err := strconv.Atoi(key)
+			if err != nil {
+				continue
+			}
+			set, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			sb.Sets = append(sb.Sets, SetScore{
+				Set:  n,
+				Home: strField(set, "home"),
+				Away: strField(set, "away"),
+			})
+		}
+		sort.Slice(sb.Sets, func(i, j int) bool { return sb.Sets[i].Set < sb.Sets[j].Set })
+	}
+
+	if pts, ok := m["points"].(string); ok {
+		sb.Points = pts
+	}
+	if len(sb.Sets) == 0 && sb.Points == "" {
+		return nil
+	}
+
+	parts := make([]string, len(sb.Sets))
+	for i, s := range sb.Sets {
+		parts[i] = s.Home + "-" + s.Away
+	}
+	sb.Display = strings.Join(parts, ",")
+	if sb.Points != "" {
+		sb.Display += " (" + sb.Points + ")"
+	}
+	return sb
+}
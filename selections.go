@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// --- SELECTION NAMES ---
+// bet365 иногда дописывает к имени исхода счёт или пометки ("Arsenal 1-0", "Nadal*"),
+// из-за чего один и тот же исход выглядит по-разному между загрузками.
+// Правила очистки задаются по виду спорта: SELECTION_CLEANUP_SOCCER=trailing_score,brackets
+// (по умолчанию SELECTION_CLEANUP, а если и он не задан — defaultCleanupRules).
+// В рынках точного счёта trailing_score не применяется: там счёт — сам исход
+// ("Arsenal 2-1" и "Arsenal 1-0" — разные исходы). Исходное NA остаётся в raw.
+
+// Правила применяются по порядку: звёздочка снимается до счёта, иначе "Arsenal 1-0*" не очистится.
+var defaultCleanupRules = []string{"asterisk", "trailing_score", "whitespace"}
+
+var (
+	reTrailingScore = regexp.MustCompile(`\s+\d+\s*[-:]\s*\d+$`)
+	reBrackets      = regexp.MustCompile(`\s*[(\[][^)\]]*[)\]]$`)
+	reSpaces        = regexp.MustCompile(`\s+`)
+)
+
+var cleanupRules = map[string]func(string) string{
+	"trailing_score": func(s string) string { return reTrailingScore.ReplaceAllString(s, "") },
+	"brackets":       func(s string) string { return reBrackets.ReplaceAllString(s, "") },
+	"asterisk":       func(s string) string { return strings.TrimRight(s, "* ") },
+	"whitespace":     func(s string) string { return strings.TrimSpace(reSpaces.ReplaceAllString(s, " ")) },
+}
+
+var warnedRules sync.Map
+
+func cleanSelectionName(sport, marketName, name string) string {
+	rules := getEnvList("SELECTION_CLEANUP_"+strings.ToUpper(sport),
+		getEnvList("SELECTION_CLEANUP", defaultCleanupRules))
+	correctScore := isCorrectScoreMarket(marketName)
+	for _, r := range rules {
+		if r == "trailing_score" && correctScore {
+			continue
+		}
+		fn, ok := cleanupRules[r]
+		if !ok {
+			if _, seen := warnedRules.LoadOrStore(r, true); !seen {
+				log.Printf("⚠️ Unknown selection cleanup rule %q", r)
+			}
+			continue
+		}
+		name = fn(name)
+	}
+	return name
+}
+
+// isCorrectScoreMarket — "Correct Score", "Half Time Correct Score" и т.п.
+func isCorrectScoreMarket(marketName string) bool {
+	return strings.Contains(strings.ToLower(marketName), "correct score")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCleanSelectionNameDefaults(t *testing.T) {
+	t.Setenv("SELECTION_CLEANUP", "")
+	t.Setenv("SELECTION_CLEANUP_SOCCER", "")
+
+	for in, want := range map[string]string{
+		"Arsenal":          "Arsenal",
+		"Arsenal 1-0":      "Arsenal",
+		"Arsenal 2 - 1":    "Arsenal",
+		"Arsenal 0:0":      "Arsenal",
+		"Nadal*":           "Nadal",
+		"Nadal *":          "Nadal",
+		"  Real   Madrid ": "Real Madrid",
+		"Man City 10-2*":   "Man City",
+		"Over 2.5":         "Over 2.5", // линия тотала — не счёт
+		"1-0":              "1-0",      // точный счёт как название исхода остаётся
+		"Arsenal (W)":      "Arsenal (W)",
+	} {
+		if got := cleanSelectionName("soccer", "Fulltime Result", in); got != want {
+			t.Errorf("cleanSelectionName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCleanSelectionNamePerSportRules(t *testing.T) {
+	t.Setenv("SELECTION_CLEANUP", "whitespace")
+	t.Setenv("SELECTION_CLEANUP_SOCCER", "trailing_score,brackets,whitespace")
+
+	tests := []struct{ sport, in, want string }{
+		{"soccer", "Arsenal (W) 1-0", "Arsenal"},
+		{"soccer", "Chelsea [Res]", "Chelsea"},
+		{"tennis", "Nadal*  (Q)", "Nadal* (Q)"}, // у тенниса только общий SELECTION_CLEANUP
+		{"tennis", "Nadal 6-4", "Nadal 6-4"},
+	}
+	for _, tt := range tests {
+		if got := cleanSelectionName(tt.sport, "Match Winner", tt.in); got != tt.want {
+			t.Errorf("%s: cleanSelectionName(%q) = %q, want %q", tt.sport, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCleanSelectionNameKeepsRaw(t *testing.T) {
+	t.Setenv("SELECTION_CLEANUP", "")
+	t.Setenv("SELECTION_CLEANUP_SOCCER", "")
+
+	odds, _ := parseOddsJSON(t, `{"success":1,"results":[[
+		{"type":"MG","ID":"40","NA":"Fulltime Result"},
+		{"type":"PA","ID":"1","NA":"Arsenal 1-0","OD":"6/5"}]]}`, "soccer")
+	if len(odds) != 1 || odds[0].SelectionName != "Arsenal" {
+		t.Fatalf("odds = %+v, want one clean selection", odds)
+	}
+	if want := `"NA":"Arsenal 1-0"`; !strings.Contains(odds[0].Raw, want) {
+		t.Errorf("raw = %q, want original NA %s", odds[0].Raw, want)
+	}
+}
+
+func TestCleanSelectionNameCorrectScore(t *testing.T) {
+	t.Setenv("SELECTION_CLEANUP", "")
+	t.Setenv("SELECTION_CLEANUP_SOCCER", "")
+
+	odds, _ := parseOddsJSON(t, `{"success":1,"results":[[
+		{"type":"MG","ID":"43","NA":"Correct Score"},
+		{"type":"PA","ID":"1","NA":"Arsenal 2-1","OD":"8/1"},
+		{"type":"PA","ID":"2","NA":"Arsenal 1-0*","OD":"6/1"},
+		{"type":"PA","ID":"3","NA":"Draw 0-0","OD":"9/1"}]]}`, "soccer")
+	var names []string
+	keys := map[string]bool{}
+	for _, o := range odds {
+		names = append(names, o.SelectionName)
+		keys[compareKey(o.MarketKey, o.MarketName, o.Line, o.SelectionName)] = true
+	}
+	// счёт остаётся, остальные правила по-прежнему работают
+	if want := []string{"Arsenal 2-1", "Arsenal 1-0", "Draw 0-0"}; !slices.Equal(names, want) {
+		t.Fatalf("names = %q, want %q", names, want)
+	}
+	if len(keys) != len(odds) {
+		t.Errorf("compare keys = %v, want one per selection", keys)
+	}
+}
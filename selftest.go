@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- SELF-TEST ---
+// Канарейка для мониторинга: раз в SELFTEST_INTERVAL (0 — выключено) проверяет путь данных
+// без полного синка — один запрос live-фида первого вида спорта + разбор, и пробную запись
+// в БД (временная таблица в откатываемой транзакции). Результат и задержки — в GET /healthz.
+// Как и задачи планировщика, на паузе и в окна обслуживания не запускается.
+
+type selfTestStep struct {
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+type selfTestResult struct {
+	At       time.Time    `json:"at"`
+	OK       bool         `json:"ok"`
+	Upstream selfTestStep `json:"upstream"`
+	DB       selfTestStep `json:"db"`
+}
+
+var selfTest struct {
+	mu       sync.RWMutex
+	last     *selfTestResult
+	failures int // подряд
+}
+
+func startSelfTest(ctx context.Context, h *dbHandle) {
+	every := getEnvDuration("SELFTEST_INTERVAL", 0)
+	if every <= 0 {
+		return
+	}
+	go runEvery(ctx, "selftest", every, func(ctx context.Context) {
+		res := runSelfTest(ctx, h.Pool())
+		if !res.OK {
+			logf(ctx, "🚨 Self-test failed: upstream=%q db=%q", res.Upstream.Error, res.DB.Error)
+		}
+	})
+	log.Printf("🩺 Self-test every %s", every)
+}
+
+func runSelfTest(ctx context.Context, db *pgxpool.Pool) selfTestResult {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	res := selfTestResult{At: time.Now()}
+	res.Upstream = timeStep(func() (string, error) {
+		sport := sports[0]
+		body, err := upstreamFetch(ctx, upstreamURL(url.Values{"task": {"live"}, "bookmaker": {"bet365"}, "sport": {sport}}),
+			archiveMeta{Task: "live", Sport: sport})
+		if err != nil {
+			return "", err
+		}
+		games, err := parseLiveGames(body, sport)
+		if err != nil {
+			return "", fmt.Errorf("parse: %w", err)
+		}
+		return fmt.Sprintf("live/%s: %d games", sport, len(games)), nil
+	})
+	res.DB = timeStep(func() (string, error) {
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer tx.Rollback(ctx)
+		if _, err := tx.Exec(ctx, `CREATE TEMP TABLE selftest_probe (ts TIMESTAMPTZ) ON COMMIT DROP`); err != nil {
+			return "", err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO selftest_probe VALUES (now())`); err != nil {
+			return "", err
+		}
+		return "", nil
+	})
+	res.OK = res.Upstream.OK && res.DB.OK
+
+	selfTest.mu.Lock()
+	selfTest.last = &res
+	if res.OK {
+		selfTest.failures = 0
+	} else {
+		selfTest.failures++
+	}
+	selfTest.mu.Unlock()
+	return res
+}
+
+func timeStep(fn func() (string, error)) selfTestStep {
+	start := time.Now()
+	detail, err := fn()
+	step := selfTestStep{OK: err == nil, LatencyMS: time.Since(start).Milliseconds(), Detail: detail}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	return step
+}
+
+// GET /healthz — процесс жив (всегда 200) и результат последнего self-test:
+// status ok / failing / disabled / pending (ещё не запускался).
+func healthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		selfTest.mu.RLock()
+		defer selfTest.mu.RUnlock()
+
+		status := "pending"
+		switch {
+		case getEnvDuration("SELFTEST_INTERVAL", 0) <= 0:
+			status = "disabled"
+		case selfTest.last == nil:
+		case selfTest.last.OK:
+			status = "ok"
+		default:
+			status = "failing"
+		}
+		c.JSON(200, gin.H{
+			"status":               status,
+			"self_test":            selfTest.last,
+			"consecutive_failures": selfTest.failures,
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// --- SHUTDOWN ---
+// SIGINT/SIGTERM: HTTP-сервер перестаёт принимать запросы, планировщик и компакция не запускают
+// новых прогонов, а идущий синк доводит текущую транзакцию до commit и останавливается между
+// матчами. Всё это ждём не дольше SHUTDOWN_GRACE (30s), потом main закрывает пулы БД.
+//
+// Прогоны синка работают в контексте без отмены (syncContext, runEvery): отмена по сигналу
+// оборвала бы батч посреди транзакции. Вместо этого длинные циклы спрашивают work.stopping().
+
+var errShuttingDown = errors.New("server is shutting down")
+
+// workTracker считает идущие синки и компакции, чтобы при остановке дождаться их.
+type workTracker struct {
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+var work = &workTracker{}
+
+// begin регистрирует прогон; после stop новые прогоны получают errShuttingDown.
+func (w *workTracker) begin() (done func(), err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return nil, errShuttingDown
+	}
+	w.wg.Add(1)
+	return w.wg.Done, nil
+}
+
+func (w *workTracker) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+}
+
+func (w *workTracker) stopping() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stopped
+}
+
+// wait ждёт завершения зарегистрированных прогонов; false — вышел срок ctx.
+func (w *workTracker) wait(ctx context.Context) bool {
+	finished := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// serve обслуживает HTTP до отмены ctx (сигнал), затем останавливается в пределах SHUTDOWN_GRACE.
+func serve(ctx context.Context, srv *http.Server) {
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	select {
+	case err := <-errc:
+		log.Printf("❌ HTTP server error: %v", err)
+		return
+	case <-ctx.Done():
+	}
+
+	grace := getEnvDuration("SHUTDOWN_GRACE", 30*time.Second)
+	log.Printf("🛑 Shutting down, waiting up to %s for in-flight syncs", grace)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	// сначала запрет новых прогонов: иначе Shutdown ждал бы, пока HTTP-синк пройдёт все матчи
+	work.stop()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ HTTP shutdown: %v", err)
+	}
+	if !work.wait(shutdownCtx) {
+		log.Printf("⚠️ In-flight syncs did not finish within %s", grace)
+		return
+	}
+	log.Printf("👋 Shutdown complete")
+}
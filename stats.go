@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- SHARED STATE ---
+// Всё, что пишут синхронизации и читают HTTP-хендлеры, хранится под мьютексом
+// (кэш и circuit breaker — со своими мьютексами внутри). Конфиг из env
+// (sports, oddsDecimalPlaces, ...) заполняется в main до старта сервера и дальше только читается.
+
+type runStats struct {
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastCount int        `json:"last_count"`
+	LastError string     `json:"last_error,omitempty"`
+	ErrorKind string     `json:"error_kind,omitempty"` // upstream / parse / db, см. errorKind
+	Runs      int        `json:"runs"`
+}
+
+type syncState struct {
+	mu         sync.RWMutex
+	games      runStats
+	odds       runStats
+	compaction runStats // LastCount — сколько строк price_events удалено
+}
+
+var state = &syncState{}
+
+func (s *syncState) record(rs *runStats, count int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	rs.LastRun = &now
+	rs.LastCount = count
+	rs.Runs++
+	rs.LastError, rs.ErrorKind = "", ""
+	if err != nil {
+		rs.LastError, rs.ErrorKind = err.Error(), errorKind(err)
+	}
+}
+
+func (s *syncState) recordGames(count int, err error) { s.record(&s.games, count, err) }
+func (s *syncState) recordOdds(count int, err error)  { s.record(&s.odds, count, err) }
+func (s *syncState) recordCompaction(count int, err error) {
+	s.record(&s.compaction, count, err)
+}
+
+func (s *syncState) snapshot() gin.H {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return gin.H{"games_sync": s.games, "odds_update": s.odds, "compaction": s.compaction}
+}
+
+// --- FETCH SANITY ---
+// По каждому фиду (pre/soccer, live/tennis, ...) держим последние fetchWindow размеров ответа.
+// Если новый ответ меньше FETCH_SANITY_PERCENT процентов (по умолчанию 20) от среднего — предупреждение:
+// так видна тихая деградация апстрима, когда он отдаёт 2 матча вместо обычных 200.
+
+const fetchWindow = 10
+
+type fetchCount struct {
+	Last    int       `json:"last"`
+	Average float64   `json:"average"`
+	At      time.Time `json:"at"`
+	Suspect bool      `json:"suspect"`
+	history []int
+}
+
+type fetchCounts struct {
+	mu    sync.RWMutex
+	feeds map[string]*fetchCount
+}
+
+var feedCounts = &fetchCounts{feeds: map[string]*fetchCount{}}
+
+func (f *fetchCounts) observe(ctx context.Context, feed string, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fc := f.feeds[feed]
+	if fc == nil {
+		fc = &fetchCount{}
+		f.feeds[feed] = fc
+	}
+
+	var avg float64
+	if len(fc.history) > 0 {
+		sum := 0
+		for _, v := range fc.history {
+			sum += v
+		}
+		avg = float64(sum) / float64(len(fc.history))
+	}
+
+	ratio := float64(getEnvInt("FETCH_SANITY_PERCENT", 20)) / 100
+	fc.Suspect = len(fc.history) >= 3 && float64(n) < avg*ratio
+	if fc.Suspect {
+		logf(ctx, "⚠️ Feed %s returned %d items, rolling average is %.1f", feed, n, avg)
+	}
+
+	fc.Last, fc.Average, fc.At = n, avg, time.Now()
+	fc.history = append(fc.history, n)
+	if len(fc.history) > fetchWindow {
+		fc.history = fc.history[1:]
+	}
+}
+
+func (f *fetchCounts) snapshot() map[string]fetchCount {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make(map[string]fetchCount, len(f.feeds))
+	for k, v := range f.feeds {
+		out[k] = *v
+	}
+	return out
+}
+
+// GET /stats — состояние сервиса для операторов.
+func statsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"sync":        state.snapshot(),
+			"feeds":       feedCounts.snapshot(),
+			"breaker":     upstreamBreaker.stats(),
+			"maintenance": maintenanceStats(),
+			"ingestion":   pause.stats(),
+		})
+	}
+}
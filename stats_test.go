@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Тесты этого файла имеют смысл под go test -race (так их гоняет CI): синки пишут
+// общее состояние, а /stats читает его из другой горутины.
+
+func statsRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/stats", statsHandler())
+	return r
+}
+
+// readStats опрашивает /stats, пока не закроется stop.
+func readStats(t *testing.T, r http.Handler, stop <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET /stats = %d", rec.Code)
+			return
+		}
+	}
+}
+
+func TestStatsReadsDuringStateWrites(t *testing.T) {
+	saved := upstreamBreaker
+	defer func() { upstreamBreaker = saved }()
+	upstreamBreaker = newCircuitBreaker(3, time.Millisecond)
+
+	r := statsRouter()
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for range 4 {
+		readers.Add(1)
+		go readStats(t, r, stop, &readers)
+	}
+
+	ctx := context.Background()
+	var writers sync.WaitGroup
+	for i := range 50 {
+		writers.Add(4)
+		go func() {
+			defer writers.Done()
+			state.recordGames(i, nil)
+			state.recordOdds(i, &UpstreamError{Task: "liveodds", Err: errors.New("returned 502")})
+		}()
+		go func() {
+			defer writers.Done()
+			state.recordCompaction(i, nil)
+		}()
+		go func() {
+			defer writers.Done()
+			feedCounts.observe(ctx, "pre/soccer", i)
+			feedCounts.observe(ctx, "live/tennis", i)
+		}()
+		go func() {
+			defer writers.Done()
+			if err := upstreamBreaker.allow(); err == nil {
+				upstreamBreaker.failure()
+			}
+			upstreamBreaker.success()
+		}()
+	}
+	writers.Wait()
+	close(stop)
+	readers.Wait()
+}
+
+func TestConcurrentSyncsAndStats(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	// открытый breaker: синки проходят весь путь (локи, state, sync_runs) без сети
+	saved := upstreamBreaker
+	defer func() { upstreamBreaker = saved }()
+	upstreamBreaker = newCircuitBreaker(1, time.Hour)
+	upstreamBreaker.failure()
+
+	if err := upsertGames(ctx, pool, []Game{fixtureGame("live1", "soccer", "live", "1", time.Hour)}); err != nil {
+		t.Fatalf("upsertGames: %v", err)
+	}
+	before := state.snapshot()
+
+	r := statsRouter()
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for range 2 {
+		readers.Add(1)
+		go readStats(t, r, stop, &readers)
+	}
+
+	var syncs sync.WaitGroup
+	for range 5 {
+		syncs.Add(4)
+		go func() {
+			defer syncs.Done()
+			syncGames(ctx, pool, []string{"soccer"}, gameSources)
+		}()
+		go func() {
+			defer syncs.Done()
+			syncGames(ctx, pool, sports, gameSources)
+		}()
+		go func() {
+			defer syncs.Done()
+			updateLiveOdds(ctx, pool, "")
+		}()
+		go func() {
+			defer syncs.Done()
+			updateLiveOdds(ctx, pool, "soccer")
+		}()
+	}
+	syncs.Wait()
+	close(stop)
+	readers.Wait()
+
+	after := state.snapshot()
+	games, odds := after["games_sync"].(runStats), after["odds_update"].(runStats)
+	if games.Runs <= before["games_sync"].(runStats).Runs || games.ErrorKind != "upstream" {
+		t.Errorf("games_sync = %+v, want new runs failing with upstream error", games)
+	}
+	if odds.Runs <= before["odds_update"].(runStats).Runs {
+		t.Errorf("odds_update = %+v, want new runs", odds)
+	}
+
+	var runs int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM sync_runs").Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if runs == 0 {
+		t.Error("sync_runs is empty, want recorded runs")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import "github.com/gin-gonic/gin"
+
+// --- SUSPENDED ---
+
+// SuspendedGameView — активный матч, у которого прямо сейчас есть приостановленные исходы.
+type SuspendedGameView struct {
+	GameView
+	SuspendedSelections int `json:"suspended_selections"`
+	SuspendedMarkets    int `json:"suspended_markets"`
+	OpenSelections      int `json:"open_selections"`
+}
+
+// GET /api/games/suspended?sport=soccer,tennis&limit=100 — матчи с приостановленными рынками
+// (часто признак эпизода на поле), больше всего приостановленных исходов — первыми.
+// Учитываются только матчи в ACTIVE_TIME_STATUSES; закрытые рынки (status=closed) не считаются.
+func suspendedGamesHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		limit, err := queryInt(c, "limit", 100, 1, 1000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		sportList, err := queryList(c, "sport", sports...)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT g.game_id, g.sport, g.league, g.home_team, g.away_team, g.scores, g.time_status,
+			       g.starts_at, g.starts_at_estimated, g.scores_detail,
+			       s.suspended, s.suspended_markets, s.open
+			FROM games g
+			JOIN (
+				SELECT game_id,
+				       COUNT(*) FILTER (WHERE status = 'suspended')                  AS suspended,
+				       COUNT(DISTINCT market_id) FILTER (WHERE status = 'suspended') AS suspended_markets,
+				       COUNT(*) FILTER (WHERE status = 'open')                       AS open
+				FROM liveodds
+				WHERE status <> 'closed'
+				GROUP BY game_id
+				HAVING COUNT(*) FILTER (WHERE status = 'suspended') > 0
+			) s USING (game_id)
+			WHERE g.time_status = ANY($1) AND (COALESCE(cardinality($2::text[]), 0) = 0 OR g.sport = ANY($2))
+			ORDER BY s.suspended DESC, g.starts_at NULLS LAST, g.game_id
+			LIMIT $3`, activeTimeStatuses, sportList, limit)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		out, err := scanAll(rows, func(r rowScanner) (SuspendedGameView, error) {
+			var g SuspendedGameView
+			err := r.Scan(&g.GameID, &g.Sport, &g.League, &g.Home, &g.Away, &g.Scores, &g.Time,
+				&g.StartsAt, &g.StartsAtEstimated, &g.ScoresDetail,
+				&g.SuspendedSelections, &g.SuspendedMarkets, &g.OpenSelections)
+			return g, err
+		})
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		if out == nil {
+			out = []SuspendedGameView{}
+		}
+		respondList(c, "games", out, len(out), limit, 0, nil)
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/singleflight"
+)
+
+// --- SYNC ---
+
+// syncContext — контекст для синка из HTTP-запроса: сохраняет request ID для логов,
+// но не наследует дедлайн REQUEST_TIMEOUT_SEC — прогон по всем live-матчам идёт дольше.
+func syncContext(c *gin.Context) context.Context {
+	return context.WithoutCancel(c.Request.Context())
+}
+
+// Одновременные прогоны одной задачи (планировщик + ручной вызов, два /sync-games) не дублируются:
+// второй вызов с тем же набором дожидается уже идущего и получает его результат. Ключи —
+// "games/<sports>/<sources>" и "odds/<sport>" ("odds/" — все виды спорта).
+var syncFlight singleflight.Group
+
+// Пересекающиеся, но разные наборы (все виды спорта и один soccer) singleflight не объединяет —
+// их сериализуют syncLocks: прогон берёт мьютекс "games/<sport>" или "odds/<sport>" на каждый
+// свой вид спорта (прогон по всем — на все из SPORTS), поэтому два прогона одного вида спорта
+// не ходят в апстрим и не пишут одни и те же строки одновременно.
+var syncLocks = &keyedLocks{locks: map[string]*sync.Mutex{}}
+
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock берёт мьютексы task/<sport> для всех sportList в отсортированном порядке — без взаимных
+// блокировок между наборами. Если какой-то занят, пишет в лог, что ждёт.
+func (k *keyedLocks) lock(ctx context.Context, task string, sportList []string) (unlock func()) {
+	keys := make([]string, 0, len(sportList))
+	for _, sport := range sportList {
+		keys = append(keys, task+"/"+sport)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	held := make([]*sync.Mutex, 0, len(keys))
+	for _, key := range keys {
+		k.mu.Lock()
+		m := k.locks[key]
+		if m == nil {
+			m = &sync.Mutex{}
+			k.locks[key] = m
+		}
+		k.mu.Unlock()
+		if !m.TryLock() {
+			logf(ctx, "⏳ %s is running in another sync, waiting", key)
+			m.Lock()
+		}
+		held = append(held, m)
+	}
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+		}
+	}
+}
+
+type oddsResult struct {
+	inserted int
+	warnings []string
+}
+
+// syncGames загружает матчи выбранных видов спорта и источников (pre / live) и сохраняет их.
+// Результат попадает в /stats.
+func syncGames(ctx context.Context, db *pgxpool.Pool, sportList, sources []string) (int, error) {
+	key := "games/" + strings.Join(sportList, ",") + "/" + strings.Join(sources, ",")
+	v, err, shared := syncFlight.Do(key, func() (any, error) {
+		done, err := work.begin()
+		if err != nil {
+			return 0, err
+		}
+		defer done()
+		defer syncLocks.lock(ctx, "games", sportList)()
+		started := time.Now()
+		n, err := runSyncGames(ctx, db, sportList, sources)
+		recordSyncRun(ctx, db, SyncRunView{
+			Task: "games", Sport: strings.Join(sportList, ","), Source: strings.Join(sources, ","),
+			StartedAt: started, Count: n,
+		}, err)
+		return n, err
+	})
+	if shared {
+		logf(ctx, "🔁 Games sync %s shared with a concurrent run", key)
+	}
+	return v.(int), err
+}
+
+func runSyncGames(ctx context.Context, db *pgxpool.Pool, sportList, sources []string) (int, error) {
+	all, err := fetchAllGames(ctx, sportList, sources)
+	if err != nil {
+		state.recordGames(0, err)
+		return 0, err
+	}
+	if err := upsertGames(ctx, db, all); err != nil {
+		state.recordGames(0, err)
+		return 0, err
+	}
+	state.recordGames(len(all), nil)
+	return len(all), nil
+}
+
+// updateLiveOdds обновляет коэффициенты текущих live-матчей (sport == "" — всех видов спорта).
+// Ошибки по отдельным матчам не прерывают прогон, а возвращаются как предупреждения.
+func updateLiveOdds(ctx context.Context, db *pgxpool.Pool, sport string) (int, []string, error) {
+	key := "odds/" + sport
+	v, err, shared := syncFlight.Do(key, func() (any, error) {
+		done, err := work.begin()
+		if err != nil {
+			return oddsResult{}, err
+		}
+		defer done()
+		lockSports := []string{sport}
+		if sport == "" {
+			lockSports = sports
+		}
+		defer syncLocks.lock(ctx, "odds", lockSports)()
+		started := time.Now()
+		inserted, warnings, err := runUpdateLiveOdds(ctx, db, sport)
+		recordSyncRun(ctx, db, SyncRunView{
+			Task: "odds", Sport: sport, StartedAt: started, Count: inserted, Warnings: len(warnings),
+		}, err)
+		return oddsResult{inserted, warnings}, err
+	})
+	if shared {
+		logf(ctx, "🔁 Odds update %s shared with a concurrent run", key)
+	}
+	res := v.(oddsResult)
+	return res.inserted, res.warnings, err
+}
+
+func runUpdateLiveOdds(ctx context.Context, db *pgxpool.Pool, sport string) (int, []string, error) {
+	warnings := []string{}
+	warn := func(format string, args ...any) {
+		// warnings уходят клиенту в ответе /sync — секреты апстрима из текста вырезаем
+		msg := redactSecrets(fmt.Sprintf(format, args...))
+		logf(ctx, "❌ %s", msg)
+		warnings = append(warnings, msg)
+	}
+
+	if getEnvBool("LIVE_EXPIRE_STALE", false) {
+		if n, err := expireStaleLiveGames(ctx, db); err != nil {
+			warn("Expire stale live games error: %v", err)
+		} else if n > 0 {
+			logf(ctx, "🧟 Marked %d stale live games as ended", n)
+		}
+	}
+
+	gameIDs, err := fetchLiveGameIDs(ctx, db, sport)
+	if err != nil {
+		state.recordOdds(0, err)
+		return 0, warnings, err
+	}
+
+	inserted := 0
+	for i, id := range gameIDs {
+		// при остановке дописываем текущий матч, а следующие не начинаем
+		if work.stopping() {
+			warn("Shutting down: odds update stopped, %d of %d games skipped", len(gameIDs)-i, len(gameIDs))
+			break
+		}
+		if i > 0 {
+			time.Sleep(fetchPause())
+		}
+		// без вида спорта коэффициенты записались бы с sport="" — такой матч пропускаем
+		// (пустой sport бывает у строк, записанных до появления колонки)
+		gameSport, err := getGameSport(ctx, db, id)
+		if err == nil && gameSport == "" {
+			err = errors.New("game has no sport")
+		}
+		if err != nil {
+			warn("Sport lookup error for %s, game skipped: %v", id, err)
+			continue
+		}
+		odds, incidents, err := fetchLiveOdds(ctx, id, gameSport)
+		if err != nil {
+			warn("Fetch odds error for %s: %v", id, err)
+			continue
+		}
+		if err := insertIncidents(ctx, db, incidents); err != nil {
+			warn("Insert incidents error for %s: %v", id, err)
+		}
+		if err := insertLiveOdds(ctx, db, odds); err != nil {
+			warn("Insert odds error for %s: %v", id, err)
+			continue
+		}
+		inserted += len(odds)
+	}
+	state.recordOdds(inserted, nil)
+	return inserted, warnings, nil
+}
+
+// GET|POST /sync-games?sport=soccer&source=pre — синк матчей. sport и source — списки через запятую;
+// без них — все виды спорта из SPORTS и оба источника, как раньше. Для отдельных cron-задач
+// по виду спорта/источнику с разной частотой.
+func syncGamesHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sportList, err := queryList(c, "sport", sports...)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		sources, err := queryList(c, "source", gameSources...)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		if len(sportList) == 0 {
+			sportList = sports
+		}
+		if len(sources) == 0 {
+			sources = gameSources
+		}
+		count, err := syncGames(syncContext(c), h.Pool(), sportList, sources)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"status": "✅ Games synced", "count": count, "sports": sportList, "sources": sources})
+	}
+}
+
+// POST /sync — матчи, затем коэффициенты по ставшим текущими live-матчам.
+// Если синк матчей упал, коэффициенты не трогаем: список live был бы устаревшим.
+func syncHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		ctx := syncContext(c)
+		games, err := syncGames(ctx, db, sports, gameSources)
+		if err != nil {
+			serverError(c, fmt.Errorf("games sync: %w", err))
+			return
+		}
+		inserted, warnings, err := updateLiveOdds(ctx, db, "")
+		if err != nil {
+			serverError(c, fmt.Errorf("odds update: %w", err))
+			return
+		}
+		c.JSON(200, gin.H{
+			"status":        "✅ Synced",
+			"games":         games,
+			"odds_inserted": inserted,
+			"warnings":      warnings,
+		})
+	}
+}
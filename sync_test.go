@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncLocksSerializeOverlappingSports(t *testing.T) {
+	k := &keyedLocks{locks: map[string]*sync.Mutex{}}
+	ctx := context.Background()
+
+	unlockSoccer := k.lock(ctx, "odds", []string{"soccer"})
+	acquired := make(chan struct{})
+	go func() {
+		unlock := k.lock(ctx, "odds", []string{"tennis", "soccer"}) // прогон по всем видам спорта
+		close(acquired)
+		unlock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("all-sports run acquired soccer while a soccer run held it")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlockSoccer()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("all-sports run did not proceed after soccer run finished")
+	}
+}
+
+func TestSyncLocksIndependentSetsAndTasks(t *testing.T) {
+	k := &keyedLocks{locks: map[string]*sync.Mutex{}}
+	ctx := context.Background()
+
+	unlockSoccer := k.lock(ctx, "odds", []string{"soccer"})
+	defer unlockSoccer()
+
+	done := make(chan struct{})
+	go func() {
+		k.lock(ctx, "odds", []string{"tennis"})()
+		k.lock(ctx, "games", []string{"soccer"})()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("disjoint sport or other task blocked on odds/soccer")
+	}
+}
+
+func TestFetchAllGamesAllFeedsFailed(t *testing.T) {
+	saved := upstreamBreaker
+	defer func() { upstreamBreaker = saved }()
+	upstreamBreaker = newCircuitBreaker(1, time.Hour)
+	upstreamBreaker.failure() // открыт: каждый фид получает UpstreamError без сети
+
+	games, err := fetchAllGames(context.Background(), []string{"soccer", "tennis"}, []string{"pre", "live"})
+	if err == nil {
+		t.Fatalf("fetchAllGames = %d games, nil error; want error when every feed fails", len(games))
+	}
+	if !errors.Is(err, errCircuitOpen) || errorKind(err) != "upstream" {
+		t.Fatalf("err = %v (kind %q), want joined upstream errors", err, errorKind(err))
+	}
+}
+
+func TestFetchAllGamesNoFeeds(t *testing.T) {
+	games, err := fetchAllGames(context.Background(), []string{"soccer"}, nil)
+	if err != nil || len(games) != 0 {
+		t.Fatalf("fetchAllGames without sources = %v, %v; want empty, nil", games, err)
+	}
+}
+
+func TestUpdateLiveOddsSkipsGameWithoutSport(t *testing.T) {
+	pool := testDB(t)
+	ctx := context.Background()
+
+	saved := upstreamBreaker
+	defer func() { upstreamBreaker = saved }()
+	upstreamBreaker = newCircuitBreaker(1, time.Hour)
+	upstreamBreaker.failure() // без сети: у матча с видом спорта — ошибка загрузки, не поиска
+
+	if err := upsertGames(ctx, pool, []Game{fixtureGame("ok", "soccer", "live", "1", time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO games (game_id, source, time_status) VALUES ('nosport', 'live', '1')`); err != nil {
+		t.Fatal(err)
+	}
+
+	inserted, warnings, err := runUpdateLiveOdds(ctx, pool, "")
+	if err != nil || inserted != 0 {
+		t.Fatalf("runUpdateLiveOdds = %d, %v; want 0, nil", inserted, err)
+	}
+	joined := strings.Join(warnings, "\n")
+	if !strings.Contains(joined, "Sport lookup error for nosport, game skipped") {
+		t.Errorf("warnings %q: want sport lookup error for nosport", warnings)
+	}
+	if strings.Contains(joined, "Fetch odds error for nosport") {
+		t.Errorf("warnings %q: game without sport was fetched", warnings)
+	}
+	if !strings.Contains(joined, "Fetch odds error for ok") {
+		t.Errorf("warnings %q: want the other game processed", warnings)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- SYNC RUNS ---
+// Журнал прогонов синка в таблице sync_runs: строка на каждый реальный прогон /sync-games,
+// /update-liveodds, /sync и планировщика (вызовы, объединённые singleflight, пишутся один раз).
+// В отличие от /stats переживает рестарт и показывает историю, а не только последний прогон.
+// Строки старше SYNC_RUNS_RETENTION (720h) удаляются при записи новых.
+
+type SyncRunView struct {
+	ID         int64     `json:"id"`
+	Task       string    `json:"task"`   // games / odds
+	Sport      string    `json:"sport"`  // через запятую; "" — все виды спорта
+	Source     string    `json:"source"` // для games: pre,live
+	RunID      string    `json:"run_id"` // request ID или имя задачи планировщика (odds/soccer#12)
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Count      int       `json:"count"`
+	Warnings   int       `json:"warnings"`
+	Error      *string   `json:"error"`
+	ErrorKind  string    `json:"error_kind,omitempty"` // upstream / parse / db, см. errorKind
+}
+
+// recordSyncRun пишет прогон в sync_runs. Ошибка записи только логируется — на результат синка не влияет.
+func recordSyncRun(ctx context.Context, db *pgxpool.Pool, run SyncRunView, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	run.RunID, _ = ctx.Value(requestIDKey{}).(string)
+	if err != nil {
+		msg := err.Error()
+		run.Error, run.ErrorKind = &msg, errorKind(err)
+	}
+	retention := getEnvDuration("SYNC_RUNS_RETENTION", 30*24*time.Hour)
+	_, dbErr := db.Exec(ctx, `
+		WITH expired AS (
+			DELETE FROM sync_runs WHERE started_at < now() - make_interval(secs => $11)
+		)
+		INSERT INTO sync_runs (task, sport, source, run_id, started_at, duration_ms, count, warnings, error, error_kind)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		run.Task, run.Sport, run.Source, run.RunID, run.StartedAt, time.Since(run.StartedAt).Milliseconds(),
+		run.Count, run.Warnings, run.Error, run.ErrorKind, retention.Seconds())
+	if dbErr != nil {
+		logf(ctx, "❌ Record sync run error: %v", dbErr)
+	}
+}
+
+// GET /api/sync-runs?limit=50&task=odds&failed=true — последние прогоны синка, новые первыми.
+func syncRunsHandler(h *dbHandle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := h.Pool()
+		limit, err := queryInt(c, "limit", 50, 1, 1000)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		task, err := queryEnum(c, "task", "", "games", "odds")
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+		failed, err := queryBool(c, "failed", false)
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+
+		rows, err := db.Query(c.Request.Context(), `
+			SELECT id, task, sport, source, run_id, started_at, duration_ms, count, warnings, error, error_kind
+			FROM sync_runs
+			WHERE ($1 = '' OR task = $1) AND (NOT $2 OR error IS NOT NULL)
+			ORDER BY started_at DESC, id DESC
+			LIMIT $3`, task, failed, limit)
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		out, err := scanAll(rows, func(r rowScanner) (SyncRunView, error) {
+			var s SyncRunView
+			err := r.Scan(&s.ID, &s.Task, &s.Sport, &s.Source, &s.RunID, &s.StartedAt, &s.DurationMS,
+				&s.Count, &s.Warnings, &s.Error, &s.ErrorKind)
+			return s, err
+		})
+		if err != nil {
+			serverError(c, err)
+			return
+		}
+		if out == nil {
+			out = []SyncRunView{}
+		}
+		respondList(c, "runs", out, len(out), limit, 0, nil)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// --- TIME STATUS ---
+// Коды time_status апстрима (строкой, см. normalizeTimeStatus):
+//
+//	0  — not started, матч ещё не начался
+//	1  — in play, идёт (перерыв между таймами апстрим тоже отдаёт как 1)
+//	2  — to be fixed, статус уточняется апстримом
+//	3  — ended, завершён
+//	4  — postponed, перенесён на другую дату
+//	5  — cancelled, отменён
+//	6  — walkover, техническая победа
+//	7  — interrupted, прерван (может продолжиться)
+//	8  — abandoned, прекращён
+//	9  — retired, отказ игрока (теннис)
+//	10 — suspended, приостановлен
+//	11 — decided by FA, результат определён федерацией
+//	99 — removed, снят из фида
+//
+// «Активные» статусы — матчи, которые показываются в /api/games и /related и за пропаданием
+// которых из фида следит markMissingGames. ACTIVE_TIME_STATUSES=0,1,2,7,10 — по умолчанию
+// всё, что ещё не закончилось и не перенесено; финальные (closedTimeStatuses) указывать нельзя.
+
+var timeStatusNames = map[string]string{
+	"0":  "not_started",
+	"1":  "in_play",
+	"2":  "to_be_fixed",
+	"3":  "ended",
+	"4":  "postponed",
+	"5":  "cancelled",
+	"6":  "walkover",
+	"7":  "interrupted",
+	"8":  "abandoned",
+	"9":  "retired",
+	"10": "suspended",
+	"11": "decided_by_fa",
+	"99": "removed",
+}
+
+var defaultActiveTimeStatuses = []string{"0", "1", "2", "7", "10"}
+
+var activeTimeStatuses = defaultActiveTimeStatuses
+
+func validateActiveTimeStatuses(codes []string) error {
+	if len(codes) == 0 {
+		return fmt.Errorf("must not be empty")
+	}
+	for _, code := range codes {
+		if _, ok := timeStatusNames[code]; !ok {
+			return fmt.Errorf("unknown time_status %q", code)
+		}
+		if slices.Contains(closedTimeStatuses, code) {
+			return fmt.Errorf("time_status %s (%s) is final and cannot be active", code, timeStatusNames[code])
+		}
+	}
+	return nil
+}
+
+// timeStatusDocs — справочник кодов для /api/schema.
+func timeStatusDocs() []map[string]any {
+	codes := make([]string, 0, len(timeStatusNames))
+	for code := range timeStatusNames {
+		codes = append(codes, code)
+	}
+	slices.SortFunc(codes, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+		return strings.Compare(a, b)
+	})
+	out := make([]map[string]any, len(codes))
+	for i, code := range codes {
+		out[i] = map[string]any{
+			"code":   code,
+			"name":   timeStatusNames[code],
+			"active": slices.Contains(activeTimeStatuses, code),
+			"final":  slices.Contains(closedTimeStatuses, code),
+		}
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// --- TX RETRY ---
+// Параллельные синки пишут в одни и те же строки games/liveodds, и Postgres иногда
+// откатывает транзакцию с serialization failure (40001) или deadlock (40P01).
+// Такие ошибки лечатся простым повтором всей транзакции:
+//
+//	DB_RETRY_ATTEMPTS=3      — всего попыток, 1 — без повторов
+//	DB_RETRY_BACKOFF=100ms   — пауза перед первым повтором, дальше удваивается (+ случайная добавка)
+
+var (
+	txRetryAttempts = 3
+	txRetryBackoff  = 100 * time.Millisecond
+)
+
+// isRetryableTxError — ошибка, после которой транзакцию можно безопасно повторить целиком.
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// withTxRetry — withTx с повтором на serialization failure / deadlock.
+// fn вызывается заново на каждой попытке, поэтому батч нужно собирать внутри неё.
+// Итоговая ошибка — DBError с what в качестве Op.
+func withTxRetry(ctx context.Context, pool *pgxpool.Pool, what string, fn func(tx pgx.Tx) error) error {
+	return dbError(what, retryTx(ctx, what, func() error { return withTx(ctx, pool, fn) }))
+}
+
+func retryTx(ctx context.Context, what string, attempt func() error) error {
+	backoff := txRetryBackoff
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= txRetryAttempts || !isRetryableTxError(err) {
+			return err
+		}
+		wait := backoff
+		if backoff > 0 {
+			wait += rand.N(backoff/2 + 1)
+		}
+		logf(ctx, "🔁 %s: retryable DB error, attempt %d/%d in %s: %v", what, n+1, txRetryAttempts, wait, err)
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func withTxRetrySettings(t *testing.T, attempts int) {
+	t.Helper()
+	savedAttempts, savedBackoff := txRetryAttempts, txRetryBackoff
+	t.Cleanup(func() { txRetryAttempts, txRetryBackoff = savedAttempts, savedBackoff })
+	txRetryAttempts, txRetryBackoff = attempts, time.Millisecond
+}
+
+func TestRetryTxRetriesSerializationFailures(t *testing.T) {
+	withTxRetrySettings(t, 3)
+
+	for _, code := range []string{"40001", "40P01"} {
+		calls := 0
+		err := retryTx(context.Background(), "test", func() error {
+			if calls++; calls < 3 {
+				return &pgconn.PgError{Code: code}
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("%s: retryTx = %v after %d calls, want success on the 3rd", code, err, calls)
+		}
+	}
+}
+
+func TestRetryTxGivesUp(t *testing.T) {
+	withTxRetrySettings(t, 3)
+
+	calls := 0
+	err := retryTx(context.Background(), "test", func() error {
+		calls++
+		return &pgconn.PgError{Code: "40001"}
+	})
+	if !isRetryableTxError(err) || calls != 3 {
+		t.Errorf("retryTx = %v after %d calls, want the 40001 error after 3", err, calls)
+	}
+
+	calls = 0
+	unique := &pgconn.PgError{Code: "23505"}
+	if err := retryTx(context.Background(), "test", func() error { calls++; return unique }); !errors.Is(err, unique) || calls != 1 {
+		t.Errorf("non-retryable: retryTx = %v after %d calls, want it returned after 1", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = retryTx(ctx, "test", func() error { calls++; return &pgconn.PgError{Code: "40001"} })
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("canceled: retryTx = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestWithTxRetryRerunsWholeTransaction(t *testing.T) {
+	pool := testDB(t)
+	withTxRetrySettings(t, 3)
+	ctx := context.Background()
+
+	calls := 0
+	err := withTxRetry(ctx, pool, "test", func(tx pgx.Tx) error {
+		calls++
+		if _, err := tx.Exec(ctx, `INSERT INTO games (game_id) VALUES ('g1')`); err != nil {
+			return err
+		}
+		if calls == 1 {
+			_, err := tx.Exec(ctx, `DO $$ BEGIN RAISE EXCEPTION 'conflict' USING ERRCODE = '40001'; END $$`)
+			return err
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("withTxRetry = %v after %d calls, want success on the 2nd", err, calls)
+	}
+	// вставка первой попытки откатилась, иначе вторая упала бы на первичном ключе
+	if n := countRows(t, pool, "games"); n != 1 {
+		t.Fatalf("games = %d rows, want 1", n)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestUpstreamGetNetworkErrorHidesToken(t *testing.T) {
+	t.Setenv("API_TOKEN", "s3cr3t-token")
+	t.Setenv("API_LOGIN", "user")
+	t.Setenv("UPSTREAM_TOKEN_HEADER", "")
+
+	// закрытый сервер — гарантированная сетевая ошибка с *url.Error внутри
+	srv := httptest.NewServer(http.NotFoundHandler())
+	base := srv.URL
+	srv.Close()
+
+	saved := upstreamBreaker
+	defer func() { upstreamBreaker = saved }()
+	upstreamBreaker = newCircuitBreaker(100, 0)
+
+	rawURL := base + "?" + url.Values{"task": {"live"}, "token": {"s3cr3t-token"}}.Encode()
+	_, err := upstreamGet(context.Background(), rawURL)
+	if err == nil {
+		t.Fatal("expected network error")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Fatalf("error leaks token: %v", err)
+	}
+	if !strings.Contains(err.Error(), "token=REDACTED") {
+		t.Fatalf("error should keep the redacted URL for debugging: %v", err)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	t.Setenv("API_TOKEN", "a+b/c==")
+	t.Setenv("API_LOGIN", "ab")
+	cases := []struct{ in, want string }{
+		{"token a+b/c== failed", "token REDACTED failed"},
+		{"get ?token=a%2Bb%2Fc%3D%3D: refused", "get ?token=REDACTED: refused"},
+		{"login ab is too short to redact", "login ab is too short to redact"},
+	}
+	for _, tc := range cases {
+		if got := redactSecrets(tc.in); got != tc.want {
+			t.Errorf("redactSecrets(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Заполняются при сборке:
+//
+//	go build -ldflags "-X main.gitCommit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	gitCommit = "dev"
+	buildTime = "unknown"
+)
+
+// GET /version — какая сборка задеплоена и с каким конфигом запущена.
+func versionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"commit":     gitCommit,
+			"build_time": buildTime,
+			"go_version": runtime.Version(),
+			"sports":     sports,
+			"bookmakers": bookmakers(),
+		})
+	}
+}